@@ -0,0 +1,95 @@
+package parsenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// An Audit records the configuration inputs a service consumed, for
+// security tooling, see Report.Audit. It contains no values, only their
+// hashes.
+type Audit struct {
+	Time    time.Time    `json:"time"`    // when the configuration was loaded
+	Service string       `json:"service"` // name of the service
+	Inputs  []AuditInput `json:"inputs"`
+}
+
+// An AuditInput describes a single variable of an Audit.
+type AuditInput struct {
+	Name   string `json:"name"`           // name of the variable
+	Field  string `json:"field"`          // path of the struct field
+	Source string `json:"source"`         // unset, env, default, or prompt
+	Secret bool   `json:"secret"`         // whether the field is tagged `secret`
+	Hash   string `json:"hash,omitempty"` // hex encoded SHA-256 of the value, empty if unset or secret
+}
+
+// Audit returns an audit record of the inputs the report was built from,
+// to be exported with WriteJSON or WriteCEF at startup:
+//
+//	var report parsenv.Report
+//	if err := parsenv.Load(&cfg, parsenv.WithReport(&report)); err != nil {
+//		log.Fatal(err)
+//	}
+//	report.Audit("billing").WriteCEF(auditLog)
+//
+// The audit is timestamped with Report.Time, or the current time if that is
+// unset. Values of secret fields are not hashed, as secrets with little
+// entropy (like short passwords) could be recovered from their hashes by
+// guessing.
+func (r *Report) Audit(service string) *Audit {
+	at := r.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	a := &Audit{Time: at.UTC(), Service: service, Inputs: []AuditInput{}}
+	for _, f := range r.Fields {
+		a.Inputs = append(a.Inputs, AuditInput{
+			Name:   f.Name,
+			Field:  f.Path,
+			Source: f.Source.String(),
+			Secret: f.Tag.Secret,
+			Hash:   f.Hash,
+		})
+	}
+	return a
+}
+
+// WriteJSON writes the audit as a single JSON object, followed by a
+// newline.
+func (a *Audit) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(a)
+}
+
+// WriteCEF writes the audit in the ArcSight Common Event Format, one event
+// per input, like:
+//
+//	CEF:0|parsenv|parsenv|1|config-input|Configuration input|1|rt=1700000000000 dproc=billing cs1Label=variable cs1=PORT cs2Label=field cs2=Port cs3Label=source cs3=env cs4Label=secret cs4=false cs5Label=sha256 cs5=9f86d0...
+func (a *Audit) WriteCEF(w io.Writer) error {
+	for _, in := range a.Inputs {
+		ext := []string{
+			"rt=" + fmt.Sprint(a.Time.UnixMilli()),
+			"dproc=" + cefEscape(a.Service),
+			"cs1Label=variable", "cs1=" + cefEscape(in.Name),
+			"cs2Label=field", "cs2=" + cefEscape(in.Field),
+			"cs3Label=source", "cs3=" + in.Source,
+			"cs4Label=secret", "cs4=" + fmt.Sprint(in.Secret),
+		}
+		if in.Hash != "" {
+			ext = append(ext, "cs5Label=sha256", "cs5="+in.Hash)
+		}
+		if _, err := fmt.Fprintf(w, "CEF:0|parsenv|parsenv|1|config-input|Configuration input|1|%s\n", strings.Join(ext, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cefEscaper escapes the values of CEF extensions.
+var cefEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+
+func cefEscape(s string) string {
+	return cefEscaper.Replace(s)
+}
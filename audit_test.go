@@ -0,0 +1,85 @@
+package parsenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportAudit(t *testing.T) {
+	var myConfig struct {
+		Port     int    `cfg:"default=8080"`
+		Password string `cfg:"secret"`
+		Region   string
+	}
+	t.Setenv("PASSWORD", "hunter2")
+	var report Report
+	if err := Load(&myConfig, WithReport(&report)); err != nil {
+		t.Fatal(err)
+	}
+	audit := report.Audit("billing=eu")
+
+	var buf bytes.Buffer
+	if err := audit.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("expected no secret values in the audit, got: %s", buf.String())
+	}
+	var decoded Audit
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	expected := []AuditInput{
+		{Name: "PORT", Field: "Port", Source: "default", Hash: "6c237681e70921603a306be9a1a5d9833fce5c1e268f52b1650970eaad0dce21"},
+		{Name: "PASSWORD", Field: "Password", Source: "env", Secret: true},
+		{Name: "REGION", Field: "Region", Source: "unset"},
+	}
+	if decoded.Service != "billing=eu" || len(decoded.Inputs) != 3 {
+		t.Fatalf("expected three inputs of billing=eu, got: %+v", decoded)
+	}
+	for i := range expected {
+		if decoded.Inputs[i] != expected[i] {
+			t.Errorf("expected %+v, got: %+v", expected[i], decoded.Inputs[i])
+		}
+	}
+
+	buf.Reset()
+	if err := audit.WriteCEF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected one event per input, got: %q", buf.String())
+	}
+	for _, expected := range []string{
+		"CEF:0|parsenv|parsenv|1|config-input|Configuration input|1|",
+		`dproc=billing\=eu`,
+		"cs1=PASSWORD",
+		"cs4=true",
+	} {
+		if !strings.Contains(lines[1], expected) {
+			t.Errorf("expected %q in the event, got: %s", expected, lines[1])
+		}
+	}
+	if strings.Contains(lines[1], "cs5") {
+		t.Errorf("expected no hash for secret variables, got: %s", lines[1])
+	}
+	if strings.Contains(lines[2], "cs5") {
+		t.Errorf("expected no hash for unset variables, got: %s", lines[2])
+	}
+}
+
+func TestReportAuditTime(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	var myConfig struct{ Port int }
+	var report Report
+	if err := Load(&myConfig, WithLookuper(MapLookuper{}), WithReport(&report), WithNow(func() time.Time { return now })); err != nil {
+		t.Fatal(err)
+	}
+	if at := report.Audit("billing").Time; !at.Equal(now) || at.Location() != time.UTC {
+		t.Errorf("expected the audit to be timestamped with Options.Now in UTC, got: %v", at)
+	}
+}
@@ -0,0 +1,163 @@
+package parsenv
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// The benchmarks use MapLookupers instead of the process environment, so
+// they measure parsenv and not os.LookupEnv, and run with -count in
+// parallel to other tests without interfering.
+//
+// Performance targets, on a contemporary laptop:
+//   - BenchmarkLoadSmall: below 5µs and 50 allocations per Load
+//   - BenchmarkLoadLarge: linear in the number of fields, below 2µs each
+//   - BenchmarkLoadSource: the overhead on top of the source itself is the
+//     same as for BenchmarkLoadLarge, i.e. values are fetched once each
+// Allocations are reported for all benchmarks, so regressions show up in
+// benchstat comparisons even if the timings are noisy.
+
+type benchSmall struct {
+	Host    string `cfg:"required"`
+	Port    int    `cfg:"default=8080"`
+	Debug   bool
+	Timeout float64 `cfg:"default=2.5"`
+}
+
+type benchNested struct {
+	Database struct {
+		Host string `cfg:"required"`
+		Port int    `cfg:"default=5432"`
+		Pool struct {
+			Min int `cfg:"default=1"`
+			Max int `cfg:"default=10"`
+		}
+	}
+	Cache struct {
+		Hosts []string
+		TTL   int `cfg:"default=60"`
+	} `cfg:"prefix=REDIS_"`
+	LogConfig
+}
+
+type benchSlices struct {
+	Hosts  []string
+	Ports  []uint16
+	Ratios []float64
+	Labels map[string]string
+}
+
+// benchLarge returns a struct type with n string and n int fields.
+func benchLarge(n int) (reflect.Type, MapLookuper) {
+	env := MapLookuper{}
+	var fields []reflect.StructField
+	for i := range n {
+		fields = append(fields,
+			reflect.StructField{Name: fmt.Sprintf("Name%d", i), Type: reflect.TypeFor[string]()},
+			reflect.StructField{Name: fmt.Sprintf("Count%d", i), Type: reflect.TypeFor[int](), Tag: `cfg:"default=1"`},
+		)
+		env[fmt.Sprintf("NAME%d", i)] = "value"
+	}
+	return reflect.StructOf(fields), env
+}
+
+// benchSource is a Source mock counting lookups, standing in for remote
+// sources like HTTPSource or RedisHash.
+type benchSource struct {
+	env     MapLookuper
+	lookups int
+}
+
+func (s *benchSource) Lookup(name string) (string, bool) {
+	val, ok, _ := s.LookupErr(name)
+	return val, ok
+}
+
+func (s *benchSource) LookupErr(name string) (string, bool, error) {
+	s.lookups++
+	val, ok := s.env[name]
+	return val, ok, nil
+}
+
+func BenchmarkLoadSmall(b *testing.B) {
+	l := WithLookuper(MapLookuper{"HOST": "localhost", "DEBUG": "yes"})
+	b.ReportAllocs()
+	for range b.N {
+		var cfg benchSmall
+		if err := Load(&cfg, l); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadNested(b *testing.B) {
+	l := WithLookuper(MapLookuper{
+		"DATABASE_HOST": "db.internal",
+		"REDIS_HOSTS":   "a.internal,b.internal",
+		"LOG_LEVEL":     "debug",
+		"SINK_PATH":     "/var/log/app",
+	})
+	b.ReportAllocs()
+	for range b.N {
+		var cfg benchNested
+		if err := Load(&cfg, l); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadSlices(b *testing.B) {
+	l := WithLookuper(MapLookuper{
+		"HOSTS":  "a.internal, b.internal, c.internal, d.internal",
+		"PORTS":  "80,443,8080,8443",
+		"RATIOS": "0.1,0.2,0.3,0.4",
+		"LABELS": "team=core,env=prod,region=eu",
+	})
+	b.ReportAllocs()
+	for range b.N {
+		var cfg benchSlices
+		if err := Load(&cfg, l); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadLarge(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprint(2*n), func(b *testing.B) {
+			t, env := benchLarge(n)
+			l := WithLookuper(env)
+			b.ReportAllocs()
+			for range b.N {
+				if err := LoadValue(reflect.New(t), l); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkLoadSource(b *testing.B) {
+	t, env := benchLarge(50)
+	src := &benchSource{env: env}
+	l := WithLookuper(src)
+	b.ReportAllocs()
+	for range b.N {
+		if err := LoadValue(reflect.New(t), l); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if perLoad := src.lookups / b.N; perLoad != 100 {
+		b.Errorf("expected 100 lookups per Load, got: %d", perLoad)
+	}
+}
+
+func BenchmarkDescribe(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		if _, err := Describe(&benchNested{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package parsenv
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// BoolWords lists the words accepted as the values of boolean fields. When
+// formatting booleans, the first word of True or False is used.
+type BoolWords struct {
+	True  []string
+	False []string
+
+	// CaseSensitive requires values to match one of the words exactly,
+	// instead of ignoring case.
+	CaseSensitive bool
+}
+
+// DefaultBoolWords are accepted as booleans, unless Options.BoolWords is set:
+// true, t, yes, y, on, and 1, or false, f, no, n, off, and 0, ignoring case.
+var DefaultBoolWords = BoolWords{
+	True:  []string{"true", "t", "yes", "y", "on", "1"},
+	False: []string{"false", "f", "no", "n", "off", "0"},
+}
+
+// StrconvBoolWords only accepts what strconv.ParseBool does, for teams that
+// don't want yes, on, and the like to be booleans:
+//
+//	parsenv.Load(&cfg, parsenv.WithBoolWords(parsenv.StrconvBoolWords))
+var StrconvBoolWords = BoolWords{
+	True:          []string{"true", "1", "t", "T", "TRUE", "True"},
+	False:         []string{"false", "0", "f", "F", "FALSE", "False"},
+	CaseSensitive: true,
+}
+
+func (bw BoolWords) isZero() bool {
+	return len(bw.True) == 0 && len(bw.False) == 0
+}
+
+func (bw BoolWords) match(words []string, word string) bool {
+	if bw.CaseSensitive {
+		return slices.Contains(words, word)
+	}
+	return slices.ContainsFunc(words, func(w string) bool {
+		return strings.EqualFold(w, word)
+	})
+}
+
+func (bw BoolWords) parse(word string) (bool, error) {
+	switch {
+	case bw.match(bw.True, word):
+		return true, nil
+	case bw.match(bw.False, word):
+		return false, nil
+	}
+	return false, fmt.Errorf("not a boolean value: %s (must be one of %s, or %s)", word, strings.Join(bw.True, ", "), strings.Join(bw.False, ", "))
+}
+
+func (bw BoolWords) format(b bool) string {
+	words := bw.False
+	if b {
+		words = bw.True
+	}
+	if len(words) == 0 {
+		return fmt.Sprint(b)
+	}
+	return words[0]
+}
+
+// boolWords returns the words accepted as booleans for the field, see
+// Options.BoolWords.
+func (td TagData) boolWords() BoolWords {
+	if td.bools != nil {
+		return *td.bools
+	}
+	return DefaultBoolWords
+}
@@ -0,0 +1,57 @@
+package parsenv
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLoadBoolWords(t *testing.T) {
+	var myConfig struct {
+		Debug   bool
+		Verbose bool
+		Color   bool
+	}
+	t.Setenv("DEBUG", "On")
+	t.Setenv("VERBOSE", "NO")
+	t.Setenv("COLOR", "1")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if !myConfig.Debug || myConfig.Verbose || !myConfig.Color {
+		t.Errorf("unexpected config: %+v", myConfig)
+	}
+
+	t.Setenv("DEBUG", "on")
+	t.Setenv("VERBOSE", "tRuE")
+	err := Load(&myConfig, WithBoolWords(StrconvBoolWords))
+	var lerr *LoadError
+	if !errors.As(err, &lerr) || len(lerr.Errs) != 2 {
+		t.Fatalf("expected two errors, got: %v", err)
+	}
+	for i, name := range []string{"DEBUG", "VERBOSE"} {
+		var perr *ParseError
+		if !errors.As(lerr.Errs[i], &perr) || perr.Name != name {
+			t.Errorf("expected a *ParseError for %s, got: %v", name, lerr.Errs[i])
+		}
+	}
+
+	custom := BoolWords{True: []string{"enabled"}, False: []string{"disabled"}}
+	t.Setenv("DEBUG", "ENABLED")
+	t.Setenv("VERBOSE", "disabled")
+	t.Setenv("COLOR", "enabled")
+	if err := Load(&myConfig, WithBoolWords(custom)); err != nil {
+		t.Fatal(err)
+	}
+	if !myConfig.Debug || myConfig.Verbose || !myConfig.Color {
+		t.Errorf("unexpected config: %+v", myConfig)
+	}
+	env, err := CommandEnv(&myConfig, nil, WithBoolWords(custom))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"DEBUG=enabled", "VERBOSE=disabled", "COLOR=enabled"}; !slices.Equal(env, expected) {
+		t.Errorf("expected %v, got: %v", expected, env)
+	}
+}
@@ -0,0 +1,58 @@
+package parsenv
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// isBytes reports whether t is a byte slice, which is read as a single value
+// instead of a list of numbers.
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// validEncoding reports whether enc is a supported value of the `encoding`
+// property.
+func validEncoding(enc string) bool {
+	switch enc {
+	case "", "raw", "base64", "base64url", "hex":
+		return true
+	}
+	return false
+}
+
+// decodeBytes decodes val according to the `encoding` property. Base64 is
+// accepted with or without padding.
+func decodeBytes(val string, td TagData) ([]byte, error) {
+	switch td.Encoding {
+	default:
+		return nil, fmt.Errorf("unknown encoding: %s", td.Encoding)
+	case "", "raw":
+		return []byte(val), nil
+	case "base64":
+		return base64.RawStdEncoding.DecodeString(strings.TrimRight(val, "="))
+	case "base64url":
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(val, "="))
+	case "hex":
+		return hex.DecodeString(val)
+	}
+}
+
+// encodeBytes is the inverse of decodeBytes.
+func encodeBytes(b []byte, td TagData) (string, error) {
+	switch td.Encoding {
+	default:
+		return "", fmt.Errorf("unknown encoding: %s", td.Encoding)
+	case "", "raw":
+		return string(b), nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString(b), nil
+	case "base64url":
+		return base64.URLEncoding.EncodeToString(b), nil
+	case "hex":
+		return hex.EncodeToString(b), nil
+	}
+}
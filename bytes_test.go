@@ -0,0 +1,75 @@
+package parsenv
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLoadBytes(t *testing.T) {
+	var myConfig struct {
+		SigningKey []byte `cfg:"encoding=base64"`
+		Token      []byte `cfg:"encoding=base64url"`
+		Salt       []byte `cfg:"encoding=hex"`
+		Raw        []byte
+	}
+	t.Setenv("SIGNING_KEY", "aGVsbG8gd29ybGQ=")
+	t.Setenv("TOKEN", "_-8")
+	t.Setenv("SALT", "deadbeef")
+	t.Setenv("RAW", "plain")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if string(myConfig.SigningKey) != "hello world" {
+		t.Errorf("expected hello world, got: %q", myConfig.SigningKey)
+	}
+	if expected := []byte{0xff, 0xef}; !reflect.DeepEqual(myConfig.Token, expected) {
+		t.Errorf("expected %x, got: %x", expected, myConfig.Token)
+	}
+	if expected := []byte{0xde, 0xad, 0xbe, 0xef}; !reflect.DeepEqual(myConfig.Salt, expected) {
+		t.Errorf("expected %x, got: %x", expected, myConfig.Salt)
+	}
+	if string(myConfig.Raw) != "plain" {
+		t.Errorf("expected plain, got: %q", myConfig.Raw)
+	}
+
+	t.Setenv("SALT", "xyz")
+	err := Load(&myConfig)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Name != "SALT" {
+		t.Errorf("expected a *ParseError for SALT, got: %v", err)
+	}
+}
+
+func TestLoadBytesInvalidEncoding(t *testing.T) {
+	var myConfig struct {
+		Key  []byte `cfg:"encoding=base32"`
+		Name string `cfg:"encoding=hex"`
+	}
+	err := Load(&myConfig)
+	var lerr *LoadError
+	if !errors.As(err, &lerr) || len(lerr.Errs) != 2 {
+		t.Fatalf("expected two errors, got: %v", err)
+	}
+	for i, field := range []string{"Key", "Name"} {
+		var terr *TagError
+		if !errors.As(lerr.Errs[i], &terr) || terr.Field != field {
+			t.Errorf("expected a *TagError for %s, got: %v", field, lerr.Errs[i])
+		}
+	}
+}
+
+func TestCommandEnvBytes(t *testing.T) {
+	cfg := struct {
+		Key  []byte `cfg:"encoding=base64"`
+		Salt []byte `cfg:"encoding=hex"`
+	}{Key: []byte("hello world"), Salt: []byte{0xde, 0xad}}
+	env, err := CommandEnv(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"KEY=aGVsbG8gd29ybGQ=", "SALT=dead"}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %#v, got: %#v", expected, env)
+	}
+}
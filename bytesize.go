@@ -0,0 +1,59 @@
+package parsenv
+
+import "fmt"
+
+// byteUnits is registered as the "bytes" UnitSystem. Decimal units are
+// powers of 1000, binary ones (KiB, and the shorthands K, M, ... used by
+// tools like Docker and the JVM) powers of 1024. There is no KB, which is
+// used for both.
+var byteUnits = UnitSystem{
+	Base: "B",
+	Units: map[string]float64{
+		"kB": 1e3, "MB": 1e6, "GB": 1e9, "TB": 1e12, "PB": 1e15, "EB": 1e18,
+		"KiB": 1 << 10, "MiB": 1 << 20, "GiB": 1 << 30, "TiB": 1 << 40, "PiB": 1 << 50, "EiB": 1 << 60,
+		"K": 1 << 10, "M": 1 << 20, "G": 1 << 30, "T": 1 << 40,
+	},
+}
+
+// ByteSize is a number of bytes, written in a human-readable way like
+// "512MiB" or "2GB" (see the "bytes" unit system in UnitSystem). Integer
+// fields tagged `unit=bytes` accept the same values.
+//
+//	var cfg struct {
+//		CacheSize     parsenv.ByteSize `cfg:"default=512MiB"`
+//		MaxUploadSize int64            `cfg:"unit=bytes;default=2GB"`
+//	}
+type ByteSize int64
+
+// ParseByteSize parses a ByteSize, like "512MiB".
+func ParseByteSize(s string) (ByteSize, error) {
+	n, err := byteUnits.parseInt(s)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("invalid byte size %q: must not be negative", s)
+	}
+	return ByteSize(n), nil
+}
+
+// String formats the size with the largest fitting unit, e.g. 1.5KiB.
+func (b ByteSize) String() string {
+	return byteUnits.formatInt(int64(b))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so that Load can parse
+// ByteSize fields.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	parsed, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
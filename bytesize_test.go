@@ -0,0 +1,63 @@
+package parsenv
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLoadByteSize(t *testing.T) {
+	var myConfig struct {
+		CacheSize     ByteSize
+		MaxUploadSize int64     `cfg:"unit=bytes;default=2GB"`
+		HeapLimit     *ByteSize `cfg:"default=512M"`
+		BufferSize    ByteSize  `cfg:"default=4096"`
+	}
+	t.Setenv("CACHE_SIZE", "1.5GiB")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.CacheSize != 3<<29 {
+		t.Errorf("expected %d, got: %d", 3<<29, myConfig.CacheSize)
+	}
+	if myConfig.MaxUploadSize != 2_000_000_000 {
+		t.Errorf("expected 2000000000, got: %d", myConfig.MaxUploadSize)
+	}
+	if myConfig.HeapLimit == nil || *myConfig.HeapLimit != 512<<20 {
+		t.Errorf("expected %d, got: %v", 512<<20, myConfig.HeapLimit)
+	}
+	if myConfig.BufferSize != 4096 {
+		t.Errorf("expected 4096, got: %d", myConfig.BufferSize)
+	}
+
+	env, err := CommandEnv(&myConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"CACHE_SIZE=1.5GiB", "MAX_UPLOAD_SIZE=2GB", "HEAP_LIMIT=512MiB", "BUFFER_SIZE=4KiB"} {
+		if !slices.Contains(env, expected) {
+			t.Errorf("expected %s, got: %v", expected, env)
+		}
+	}
+
+	for _, invalid := range []string{"-1KiB", "1.5B", "10 parsecs", "MiB", "16EiB"} {
+		t.Setenv("CACHE_SIZE", invalid)
+		err := Load(&myConfig)
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Name != "CACHE_SIZE" {
+			t.Errorf("expected a *ParseError for %q, got: %v", invalid, err)
+		}
+	}
+}
+
+func TestByteSizeString(t *testing.T) {
+	for _, tc := range []struct {
+		size     ByteSize
+		expected string
+	}{{0, "0B"}, {1000, "1kB"}, {1024, "1KiB"}, {1536, "1.5KiB"}, {1500, "1.5kB"}, {2500000, "2.5MB"}, {1234567, "1234567B"}, {5 << 40, "5TiB"}} {
+		if got := tc.size.String(); got != tc.expected {
+			t.Errorf("expected %d to be formatted as %s, got: %s", tc.size, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package parsenv
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// A charset is a set of characters, see parseCharset.
+type charset struct {
+	ranges [][2]rune // inclusive
+}
+
+// charClasses are the named classes that can be used in a `charset`.
+var charClasses = map[string][][2]rune{
+	"alpha":  {{'a', 'z'}, {'A', 'Z'}},
+	"digit":  {{'0', '9'}},
+	"alnum":  {{'a', 'z'}, {'A', 'Z'}, {'0', '9'}},
+	"lower":  {{'a', 'z'}},
+	"upper":  {{'A', 'Z'}},
+	"xdigit": {{'0', '9'}, {'a', 'f'}, {'A', 'F'}},
+	"space":  {{' ', ' '}, {'\t', '\r'}},
+	"print":  {{' ', '~'}},
+	"graph":  {{'!', '~'}},
+	"ascii":  {{0, 0x7f}},
+}
+
+// parseCharset parses the value of the `charset` property, which lists the
+// allowed characters like a bracket expression of a regular expression
+// (without the brackets): single characters, ranges like a-z, and named
+// classes like [:alnum:]. A - at the start or end is taken literally.
+//
+//	charset=[:alnum:]._-
+func parseCharset(s string) (*charset, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty charset")
+	}
+	var cs charset
+	for len(s) > 0 {
+		if strings.HasPrefix(s, "[:") {
+			name, rest, ok := strings.Cut(s[2:], ":]")
+			class, known := charClasses[name]
+			if !ok || !known {
+				return nil, fmt.Errorf("invalid charset: unknown class in %q", s)
+			}
+			cs.ranges = append(cs.ranges, class...)
+			s = rest
+			continue
+		}
+		lo, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		hi := lo
+		if len(s) > 1 && s[0] == '-' {
+			hi, size = utf8.DecodeRuneInString(s[1:])
+			s = s[1+size:]
+			if hi < lo {
+				return nil, fmt.Errorf("invalid charset: range %c-%c is reversed", lo, hi)
+			}
+		}
+		cs.ranges = append(cs.ranges, [2]rune{lo, hi})
+	}
+	return &cs, nil
+}
+
+func (cs *charset) contains(r rune) bool {
+	for _, rng := range cs.ranges {
+		if rng[0] <= r && r <= rng[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns an error for the first character of val that is not in the
+// charset.
+func (cs *charset) check(val string, td TagData) error {
+	for i, r := range val {
+		if !cs.contains(r) {
+			return fmt.Errorf("character %q at offset %d is not allowed (charset=%s)", r, i, td.Charset)
+		}
+	}
+	return nil
+}
+
+// checkLength returns an error if val is longer than Options.MaxValueLength
+// allows.
+func checkLength(val string, opts Options) error {
+	if opts.MaxValueLength > 0 && len(val) > opts.MaxValueLength {
+		return fmt.Errorf("value is %d bytes long, but at most %d are allowed", len(val), opts.MaxValueLength)
+	}
+	return nil
+}
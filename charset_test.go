@@ -0,0 +1,78 @@
+package parsenv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoadCharset(t *testing.T) {
+	var myConfig struct {
+		Tenant string   `cfg:"charset=[:alnum:]._-"`
+		Header string   `cfg:"charset=[:print:]"`
+		Ids    []string `cfg:"charset=0-9,"`
+	}
+	t.Setenv("TENANT", "acme-corp_1.eu")
+	t.Setenv("HEADER", "Bearer abc")
+	t.Setenv("IDS", "1,22,333")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, val := range map[string]string{
+		"TENANT": "acme; rm -rf /",
+		"HEADER": "abc\r\nX-Injected: 1",
+		"IDS":    "1,2a",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, val)
+			err := Load(&myConfig)
+			var perr *ParseError
+			if !errors.As(err, &perr) || perr.Name != name || !strings.Contains(perr.Error(), "is not allowed (charset=") {
+				t.Errorf("expected a *ParseError for %s, got: %v", name, err)
+			}
+		})
+	}
+}
+
+func TestParseCharset(t *testing.T) {
+	cs, err := parseCharset("a-c[:digit:]-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range "abc019-" {
+		if !cs.contains(r) {
+			t.Errorf("expected %q to be in the charset", r)
+		}
+	}
+	for _, r := range "dA_ " {
+		if cs.contains(r) {
+			t.Errorf("expected %q not to be in the charset", r)
+		}
+	}
+	for _, s := range []string{"", "z-a", "[:nope:]", "[:alnum"} {
+		if _, err := parseCharset(s); err == nil {
+			t.Errorf("expected an error for %q, got nil", s)
+		}
+	}
+}
+
+func TestLoadMaxValueLength(t *testing.T) {
+	var myConfig struct {
+		Name string
+		Port int `cfg:"default=8080"`
+	}
+	t.Setenv("NAME", strings.Repeat("a", 65))
+
+	err := Load(&myConfig, WithMaxValueLength(64))
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Name != "NAME" || !strings.Contains(perr.Error(), "value is 65 bytes long, but at most 64 are allowed") {
+		t.Errorf("expected a *ParseError for NAME, got: %v", err)
+	}
+
+	t.Setenv("NAME", strings.Repeat("a", 64))
+	if err := Load(&myConfig, WithMaxValueLength(64)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,145 @@
+// Command parsenv works with the config structs of Go packages.
+//
+// Usage:
+//
+//	parsenv scaffold [-type Config] [-o main.go] <package>
+//	parsenv simulate --env-file <file> [-type Config] [packages]
+//
+// scaffold writes a main package that loads the config struct of the
+// package, see parsenv.WriteScaffold.
+//
+// simulate reports how the config structs of the packages (./... if none are
+// given) would be populated from the env files, without running the
+// application, see parsenv.Simulate. Every package that declares a struct
+// type named by -type is simulated. --env-file may be repeated; later files
+// take precedence. simulate exits with status 1 if any of the structs would
+// fail to load.
+//
+// Both commands build a throwaway program inside the module of each package,
+// which must require github.com/cvanloo/parsenv.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+const usageText = `usage:
+	parsenv scaffold [-type Config] [-o main.go] <package>
+	parsenv simulate --env-file <file> [-type Config] [packages]
+`
+
+// errFailed is returned by commands whose outcome is negative, after they
+// have reported why.
+var errFailed = errors.New("failed")
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("parsenv: ")
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usageText)
+		os.Exit(2)
+	}
+	var err error
+	switch cmd := os.Args[1]; cmd {
+	case "scaffold":
+		err = scaffold(os.Args[2:], os.Stdout)
+	case "simulate":
+		err = simulate(os.Args[2:], os.Stdout)
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usageText)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "parsenv: unknown command: %s\n%s", cmd, usageText)
+		os.Exit(2)
+	}
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errFailed):
+		os.Exit(1)
+	case err != nil:
+		log.Fatal(err)
+	}
+}
+
+// scaffold implements `parsenv scaffold`.
+func scaffold(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("scaffold", flag.ContinueOnError)
+	typeName := fs.String("type", "Config", "name of the config struct type")
+	output := fs.String("o", "", "write to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("scaffold: expected a single package, got: %q", fs.Args())
+	}
+	pkgs, err := findConfigs(fs.Args(), *typeName)
+	if err != nil {
+		return err
+	}
+	if len(pkgs) == 0 {
+		return fmt.Errorf("scaffold: package %s declares no struct type %s", fs.Arg(0), *typeName)
+	}
+	src, err := runProgram(pkgs[0], *typeName, scaffoldProgram)
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		return os.WriteFile(*output, src, 0o644)
+	}
+	_, err = stdout.Write(src)
+	return err
+}
+
+// simulate implements `parsenv simulate`.
+func simulate(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	typeName := fs.String("type", "Config", "name of the config struct type")
+	var envFiles []string
+	fs.Func("env-file", "env file with the proposed environment (repeatable)", func(name string) error {
+		envFiles = append(envFiles, name)
+		return nil
+	})
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(envFiles) == 0 {
+		return errors.New("simulate: missing --env-file")
+	}
+	patterns := fs.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	pkgs, err := findConfigs(patterns, *typeName)
+	if err != nil {
+		return err
+	}
+	if len(pkgs) == 0 {
+		return fmt.Errorf("simulate: no package in %s declares a struct type %s", strings.Join(patterns, " "), *typeName)
+	}
+	failed := false
+	for i, pkg := range pkgs {
+		if len(pkgs) > 1 {
+			if i > 0 {
+				fmt.Fprintln(stdout)
+			}
+			fmt.Fprintf(stdout, "# %s.%s\n", pkg.ImportPath, *typeName)
+		}
+		out, err := runProgram(pkg, *typeName, simulateProgram, envFiles...)
+		stdout.Write(out)
+		if errors.Is(err, errFailed) {
+			failed = true
+		} else if err != nil {
+			return err
+		}
+	}
+	if failed {
+		return errFailed
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScaffold(t *testing.T) {
+	var stdout bytes.Buffer
+	if err := scaffold([]string{"./testdata/config"}, &stdout); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"github.com/cvanloo/parsenv/cmd/parsenv/testdata/config"`,
+		"var cfg config.Config",
+		"func run(cfg config.Config) error {",
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("expected the scaffold to contain %s, got:\n%s", want, stdout.String())
+		}
+	}
+
+	if err := scaffold([]string{"-type", "Missing", "./testdata/config"}, &stdout); err == nil {
+		t.Error("expected an error for a missing type, got nil")
+	}
+}
+
+func TestScaffoldPackageName(t *testing.T) {
+	// the package is called settings, not v2
+	var stdout bytes.Buffer
+	if err := scaffold([]string{"./testdata/go-settings/v2"}, &stdout); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), `config "github.com/cvanloo/parsenv/cmd/parsenv/testdata/go-settings/v2"`) {
+		t.Errorf("expected the package to be imported as config, got:\n%s", stdout.String())
+	}
+	dir, err := os.MkdirTemp(".", "scaffold-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), stdout.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	build := exec.Command("go", "build", "-o", filepath.Join(t.TempDir(), "prog"), "./"+dir)
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Errorf("expected the scaffold to build, got: %v\n%s", err, out)
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	dir := t.TempDir()
+	newEnv := filepath.Join(dir, "new.env")
+	if err := os.WriteFile(newEnv, []byte("HOST=db.internal\nPASSWORD=hunter2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	if err := simulate([]string{"--env-file", newEnv, "./testdata/config"}, &stdout); err != nil {
+		t.Fatal(err)
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "db.internal") || !strings.Contains(out, "8080") || strings.Contains(out, "hunter2") {
+		t.Errorf("unexpected simulation:\n%s", out)
+	}
+
+	emptyEnv := filepath.Join(dir, "empty.env")
+	if err := os.WriteFile(emptyEnv, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	if err := simulate([]string{"--env-file", emptyEnv, "./testdata/config"}, &stdout); err != errFailed {
+		t.Errorf("expected errFailed, got: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "required field: Host") {
+		t.Errorf("expected the simulation to report the missing HOST, got:\n%s", stdout.String())
+	}
+
+	if err := simulate([]string{"./testdata/config"}, &stdout); err == nil {
+		t.Error("expected an error without --env-file, got nil")
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"text/template"
+)
+
+// A goPackage is a package as listed by `go list -json`.
+type goPackage struct {
+	ImportPath string
+	Dir        string
+	Name       string
+	GoFiles    []string
+}
+
+// findConfigs returns the packages matching patterns that declare a struct
+// type called typeName. Main packages are skipped, since they can't be
+// imported.
+func findConfigs(patterns []string, typeName string) ([]goPackage, error) {
+	cmd := exec.Command("go", append([]string{"list", "-json=ImportPath,Dir,Name,GoFiles"}, patterns...)...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w", err)
+	}
+	var pkgs []goPackage
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var pkg goPackage
+		if err := dec.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("go list: %w", err)
+		}
+		if pkg.Name == "main" {
+			continue
+		}
+		ok, err := declaresStruct(pkg, typeName)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs, nil
+}
+
+// declaresStruct reports whether pkg declares a (non-generic) struct type
+// called typeName.
+func declaresStruct(pkg goPackage, typeName string) (bool, error) {
+	fset := token.NewFileSet()
+	for _, name := range pkg.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return false, err
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if _, isStruct := ts.Type.(*ast.StructType); isStruct && ts.Name.Name == typeName && ts.TypeParams == nil {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// runProgram builds the program generated by tmpl for the struct type
+// typeName of pkg inside the directory of pkg, so that it can import pkg,
+// and runs it with args. It returns the standard output of the program,
+// and errFailed if it exited with status 1.
+func runProgram(pkg goPackage, typeName string, tmpl *template.Template, args ...string) ([]byte, error) {
+	var src bytes.Buffer
+	data := struct{ Import, Type string }{pkg.ImportPath, typeName}
+	if err := tmpl.Execute(&src, data); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(pkg.Dir, "parsenv-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src.Bytes(), 0o644); err != nil {
+		return nil, err
+	}
+	prog := filepath.Join(dir, "prog")
+	if runtime.GOOS == "windows" {
+		prog += ".exe"
+	}
+	build := exec.Command("go", "build", "-o", prog, "./"+filepath.Base(dir))
+	build.Dir = pkg.Dir
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		return nil, fmt.Errorf("building program for %s: %w", pkg.ImportPath, err)
+	}
+	run := exec.Command(prog, args...)
+	run.Stderr = os.Stderr
+	out, err := run.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return out, errFailed
+	}
+	if err != nil {
+		return out, fmt.Errorf("running program for %s: %w", pkg.ImportPath, err)
+	}
+	return out, nil
+}
+
+var scaffoldProgram = template.Must(template.New("scaffold").Parse(`package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cvanloo/parsenv"
+	config {{printf "%q" .Import}}
+)
+
+func main() {
+	if err := parsenv.WriteScaffold(os.Stdout, &config.{{.Type}}{}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+`))
+
+var simulateProgram = template.Must(template.New("simulate").Parse(`package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cvanloo/parsenv"
+	config {{printf "%q" .Import}}
+)
+
+func main() {
+	env, err := parsenv.LoadDotenvFiles(os.Args[1:]...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	sim := parsenv.Simulate(&config.{{.Type}}{}, env)
+	fmt.Print(sim)
+	if sim.Err != nil {
+		os.Exit(1)
+	}
+}
+`))
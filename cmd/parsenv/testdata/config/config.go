@@ -0,0 +1,7 @@
+package config
+
+type Config struct {
+	Host     string `cfg:"required"`
+	Port     int    `cfg:"default=8080"`
+	Password string `cfg:"secret"`
+}
@@ -0,0 +1,5 @@
+package settings
+
+type Config struct {
+	Region string `cfg:"default=eu"`
+}
@@ -0,0 +1,140 @@
+package parsenv
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// WriteCompletion writes a completion script for the shell (one of "bash",
+// "zsh", or "fish") to w. The script completes the names of the variables
+// described by cfg as NAME= arguments to the program prog, and, for
+// variables with a known set of values (like booleans), the values after the
+// equals sign.
+//
+// To let the program honor these arguments, look them up with ArgsLookuper:
+//
+//	l := parsenv.MultiLookuper(parsenv.ArgsLookuper(os.Args[1:]), parsenv.EnvLookuper)
+//	err := parsenv.Load(&cfg, parsenv.WithLookuper(l))
+//
+// Then `prog DEBUG=true` behaves just like `DEBUG=true prog`.
+func WriteCompletion(w io.Writer, shell, prog string, cfg any, opts ...Option) error {
+	infos, err := Describe(cfg, opts...)
+	if err != nil {
+		return err
+	}
+	fn := "_" + shellIdent(prog) + "_parsenv"
+	switch shell {
+	default:
+		return fmt.Errorf("unsupported shell: %s", shell)
+	case "bash":
+		return writeBashCompletion(w, fn, prog, infos)
+	case "zsh":
+		return writeZshCompletion(w, fn, prog, infos)
+	case "fish":
+		return writeFishCompletion(w, prog, infos)
+	}
+}
+
+// completionValues returns the values a variable can take, or nil if they
+// are not known.
+func completionValues(fi FieldInfo) []string {
+	t := fi.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if len(fi.Tag.OneOf) > 0 && t.Kind() != reflect.Slice {
+		return fi.Tag.OneOf
+	}
+	if t.Kind() == reflect.Bool {
+		return []string{"true", "false"}
+	}
+	if t == slogLevelType || t == slogLevelVarType {
+		return []string{"debug", "info", "warn", "error"}
+	}
+	return nil
+}
+
+func writeBashCompletion(w io.Writer, fn, prog string, infos []FieldInfo) error {
+	var names []string
+	var cases strings.Builder
+	for _, fi := range infos {
+		names = append(names, fi.Name+"=")
+		if vals := completionValues(fi); vals != nil {
+			fmt.Fprintf(&cases, "\t%s=*) COMPREPLY=($(compgen -W %s -- \"${cur#*=}\")) ;;\n", shellQuote(fi.Name), shellQuote(strings.Join(vals, " ")))
+		}
+	}
+	_, err := fmt.Fprintf(w, `%[1]s() {
+	local line="${COMP_LINE:0:COMP_POINT}"
+	local cur="${line##*[[:space:]]}"
+	case "$cur" in
+%[2]s	*=*) COMPREPLY=() ;;
+	*) COMPREPLY=($(compgen -W %[3]s -- "$cur")) ;;
+	esac
+}
+complete -o nospace -o default -F %[1]s %[4]s
+`, fn, cases.String(), shellQuote(strings.Join(names, " ")), shellQuote(prog))
+	return err
+}
+
+func writeZshCompletion(w io.Writer, fn, prog string, infos []FieldInfo) error {
+	var names []string
+	var cases strings.Builder
+	for _, fi := range infos {
+		names = append(names, shellQuote(fi.Name+"="))
+		if vals := completionValues(fi); vals != nil {
+			quoted := make([]string, len(vals))
+			for i, val := range vals {
+				quoted[i] = shellQuote(val)
+			}
+			fmt.Fprintf(&cases, "\t\t%s) compadd -- %s ;;\n", shellQuote(fi.Name), strings.Join(quoted, " "))
+		}
+	}
+	_, err := fmt.Fprintf(w, `#compdef %[5]s
+%[1]s() {
+	if [[ $PREFIX == *=* ]]; then
+		local name=${PREFIX%%%%=*}
+		compset -P '*='
+		case $name in
+%[2]s		esac
+	else
+		compadd -S '' -- %[3]s
+	fi
+}
+compdef %[1]s %[4]s
+`, fn, cases.String(), strings.Join(names, " "), shellQuote(prog), prog)
+	return err
+}
+
+func writeFishCompletion(w io.Writer, prog string, infos []FieldInfo) error {
+	for _, fi := range infos {
+		if _, err := fmt.Fprintf(w, "complete -c %s -f -a %s\n", shellQuote(prog), shellQuote(fi.Name+"=")); err != nil {
+			return err
+		}
+		if vals := completionValues(fi); vals != nil {
+			candidates := make([]string, len(vals))
+			for i, val := range vals {
+				candidates[i] = fi.Name + "=" + val
+			}
+			cond := fmt.Sprintf("string match -q -- %s (commandline -ct)", shellQuote(fi.Name+"=*"))
+			if _, err := fmt.Fprintf(w, "complete -c %s -f -n %s -a %s\n", shellQuote(prog), shellQuote(cond), shellQuote(strings.Join(candidates, " "))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// shellQuote quotes s for use in bash, zsh, and fish scripts.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+var nonIdentChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// shellIdent turns s into a valid shell function name component.
+func shellIdent(s string) string {
+	return nonIdentChars.ReplaceAllString(s, "_")
+}
@@ -0,0 +1,46 @@
+package parsenv
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWriteCompletion(t *testing.T) {
+	var myConfig struct {
+		Debug    bool
+		Port     int
+		LogLevel slog.Level
+		Mode     string `cfg:"oneof=fast|safe"`
+	}
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		var out strings.Builder
+		if err := WriteCompletion(&out, shell, "my-app", &myConfig); err != nil {
+			t.Errorf("%s: %v", shell, err)
+		}
+		for _, expected := range []string{"DEBUG=", "PORT=", "LOG_LEVEL=", "true", "false", "warn", "safe", "my-app"} {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("%s: expected completion script to contain %q, got:\n%s", shell, expected, out.String())
+			}
+		}
+	}
+	if err := WriteCompletion(&strings.Builder{}, "csh", "my-app", &myConfig); err == nil {
+		t.Error("expected non-nil error, got nil")
+	}
+}
+
+func TestArgsLookuper(t *testing.T) {
+	t.Setenv("PORT", "80")
+	t.Setenv("HOST", "localhost")
+	l := MultiLookuper(ArgsLookuper([]string{"--verbose", "PORT=8080", "serve", "DEBUG="}), EnvLookuper)
+
+	if val, _ := l.Lookup("PORT"); val != "8080" {
+		t.Errorf("expected 8080, got: %s", val)
+	}
+	if val, _ := l.Lookup("HOST"); val != "localhost" {
+		t.Errorf("expected localhost, got: %s", val)
+	}
+	if val, ok := l.Lookup("DEBUG"); !ok || val != "" {
+		t.Errorf("expected DEBUG to be set to the empty string, got: %q (%t)", val, ok)
+	}
+}
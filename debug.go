@@ -0,0 +1,100 @@
+package parsenv
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+)
+
+// A RedactionPolicy decides how the value of a field is shown by
+// ConfigHandler. It returns the value to show in place of value.
+type RedactionPolicy func(fi FieldInfo, value string) string
+
+// RedactSecrets replaces the values of fields tagged `secret` with
+// "[REDACTED]".
+func RedactSecrets(fi FieldInfo, value string) string {
+	if fi.Tag.Secret {
+		return redacted
+	}
+	return value
+}
+
+// TruncateValues returns a RedactionPolicy that shortens values longer than
+// n bytes, e.g. certificates, to their first n bytes followed by "...".
+func TruncateValues(n int) RedactionPolicy {
+	return func(fi FieldInfo, value string) string {
+		if len(value) > n {
+			return value[:n] + "..."
+		}
+		return value
+	}
+}
+
+// Redactions applies several policies in order.
+//
+//	policy := parsenv.Redactions(parsenv.RedactSecrets, parsenv.TruncateValues(64))
+func Redactions(policies ...RedactionPolicy) RedactionPolicy {
+	return func(fi FieldInfo, value string) string {
+		for _, policy := range policies {
+			value = policy(fi, value)
+		}
+		return value
+	}
+}
+
+// configEntry is a field as served by ConfigHandler.
+type configEntry struct {
+	Field  string `json:"field"`
+	EnvVar string `json:"envVar"`
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+}
+
+// ConfigHandler returns an http.Handler serving the current values of the
+// fields of cfg as a JSON array, for a debug endpoint:
+//
+//	mux.Handle("GET /debug/config", requireAdmin(parsenv.ConfigHandler(&cfg, nil)))
+//
+// Values are passed through the policy before they are served. If it is nil,
+// TruncateValues(256) is used. Values of fields tagged `secret` are always
+// redacted, whatever the policy.
+//
+// The handler doesn't authenticate requests, which is up to the caller. cfg
+// must be a pointer to a struct, otherwise ConfigHandler panics. It is read
+// on every request, so the caller must make sure it isn't written to at the
+// same time, e.g. when reloading the config.
+func ConfigHandler(cfg any, policy RedactionPolicy, opts ...Option) http.Handler {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		panic("parsenv.ConfigHandler: must pass a pointer to a structure")
+	}
+	if policy == nil {
+		policy = TruncateValues(256)
+	}
+	policy = Redactions(policy, RedactSecrets)
+	o := makeOptions(opts)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		specs, errs := compileStruct(v.Elem().Type(), o)
+		if err := newLoadError(errs, o); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		entries := make([]configEntry, len(specs))
+		for i, spec := range specs {
+			fi := FieldInfo{Path: spec.path, Name: spec.name, Type: spec.field.Type, Tag: spec.tag}
+			value, err := formatValue(v.Elem().FieldByIndex(spec.index), spec.tag)
+			if err != nil {
+				value = ""
+			}
+			entries[i] = configEntry{
+				Field:  spec.path,
+				EnvVar: spec.name,
+				Type:   spec.field.Type.String(),
+				Value:  policy(fi, value),
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(entries)
+	})
+}
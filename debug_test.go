@@ -0,0 +1,54 @@
+package parsenv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigHandler(t *testing.T) {
+	cfg := struct {
+		Host     string
+		Password string `cfg:"secret"`
+		Cert     string
+		Port     int
+	}{Host: "db.internal", Password: "hunter2", Cert: strings.Repeat("A", 300), Port: 5432}
+
+	rec := httptest.NewRecorder()
+	ConfigHandler(&cfg, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected application/json, got: %s", ct)
+	}
+	var entries []configEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+	expected := []configEntry{
+		{Field: "Host", EnvVar: "HOST", Type: "string", Value: "db.internal"},
+		{Field: "Password", EnvVar: "PASSWORD", Type: "string", Value: "[REDACTED]"},
+		{Field: "Cert", EnvVar: "CERT", Type: "string", Value: strings.Repeat("A", 256) + "..."},
+		{Field: "Port", EnvVar: "PORT", Type: "int", Value: "5432"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected %v, got: %v", expected, entries)
+	}
+
+	hideHosts := func(fi FieldInfo, value string) string {
+		if fi.Name == "HOST" {
+			return "***"
+		}
+		return value
+	}
+	rec = httptest.NewRecorder()
+	ConfigHandler(&cfg, hideHosts).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+	entries = nil
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+	if entries[0].Value != "***" || entries[1].Value != "[REDACTED]" || len(entries[2].Value) != 300 {
+		t.Errorf("unexpected entries with a custom policy: %v", entries)
+	}
+}
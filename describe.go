@@ -0,0 +1,42 @@
+package parsenv
+
+import "reflect"
+
+// FieldInfo describes how Load populates a single field.
+type FieldInfo struct {
+	Path string       // path of the struct field, e.g. Database.Host
+	Name string       // name of the environment variable
+	Type reflect.Type // type of the struct field
+	Tag  TagData      // properties set in the `cfg` tag
+}
+
+// Describe returns information about every field Load would populate in cfg,
+// in the order Load visits them. Ignored and unloadable fields are omitted.
+// cfg may be a struct or a pointer to a struct, otherwise Describe panics.
+// Problems with the struct definition, such as invalid tags, are returned as
+// a *LoadError.
+//
+// Describe is meant for tooling that needs to know about the variables a
+// program reads, like help texts (see Usage), shell completions, or
+// .env.example templates (see WriteEnvExample).
+func Describe(cfg any, opts ...Option) ([]FieldInfo, error) {
+	t := reflect.TypeOf(cfg)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		panic("parsenv.Describe: must pass a structure or a pointer to a structure")
+	}
+	o := makeOptions(opts)
+	specs, errs := compileStruct(t, o)
+	infos := make([]FieldInfo, len(specs))
+	for i, spec := range specs {
+		infos[i] = FieldInfo{
+			Path: spec.path,
+			Name: spec.name,
+			Type: spec.field.Type,
+			Tag:  spec.tag,
+		}
+	}
+	return infos, newLoadError(errs, o)
+}
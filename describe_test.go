@@ -0,0 +1,174 @@
+package parsenv
+
+import (
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	var myConfig struct {
+		Ignored  string `cfg:"-"`
+		Debug    bool
+		Database struct {
+			Host     string `cfg:"required"`
+			Password string `cfg:"secret;name=PGPASSWORD"`
+		}
+	}
+	infos, err := Describe(&myConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []FieldInfo{
+		{Path: "Debug", Name: "DEBUG", Type: reflect.TypeFor[bool]()},
+		{Path: "Database.Host", Name: "DATABASE_HOST", Type: reflect.TypeFor[string](), Tag: TagData{Required: true}},
+		{Path: "Database.Password", Name: "PGPASSWORD", Type: reflect.TypeFor[string](), Tag: TagData{Name: "PGPASSWORD", Secret: true}},
+	}
+	if !reflect.DeepEqual(infos, expected) {
+		t.Errorf("expected %#v, got: %#v", expected, infos)
+	}
+}
+
+func TestFieldInfoExample(t *testing.T) {
+	var myConfig struct {
+		Host     string `cfg:"example=db.internal"`
+		Port     int    `cfg:"default=5432"`
+		Debug    bool
+		Ratio    float64
+		Hosts    []string `cfg:"sep=|"`
+		Labels   map[string]int
+		Deadline time.Time `cfg:"layout=2006-01-02"`
+	}
+	infos, err := Describe(&myConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"db.internal", "5432", "true", "0.5", "example|example", "example=1", "2006-01-02"}
+	for i, fi := range infos {
+		example := fi.Example()
+		if example != expected[i] {
+			t.Errorf("%s: expected %s, got: %s", fi.Name, expected[i], example)
+		}
+		if _, err := parseValue(fi.Type, example, fi.Tag); err != nil {
+			t.Errorf("%s: example %s doesn't parse: %s", fi.Name, example, err)
+		}
+	}
+}
+
+func TestFieldInfoExampleNetip(t *testing.T) {
+	var myConfig struct {
+		Gateway   netip.Addr
+		Listen    netip.AddrPort
+		Allowlist []netip.Prefix
+	}
+	infos, err := Describe(&myConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"192.0.2.1", "192.0.2.1:8080", "192.0.2.0/24,192.0.2.0/24"}
+	for i, fi := range infos {
+		example := fi.Example()
+		if example != expected[i] {
+			t.Errorf("%s: expected %s, got: %s", fi.Name, expected[i], example)
+		}
+		if _, err := parseValue(fi.Type, example, fi.Tag); err != nil {
+			t.Errorf("%s: example %s doesn't parse: %s", fi.Name, example, err)
+		}
+	}
+}
+
+func TestFieldInfoExampleBounds(t *testing.T) {
+	var myConfig struct {
+		Port    int           `cfg:"min=1024;max=65535"`
+		Retries int           `cfg:"max=-1"`
+		Timeout time.Duration `cfg:"min=5s"`
+		Workers []uint        `cfg:"min=2"`
+	}
+	infos, err := Describe(&myConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"1024", "-1", "5s", "2,2"}
+	l := MapLookuper{}
+	for i, fi := range infos {
+		if example := fi.Example(); example != expected[i] {
+			t.Errorf("%s: expected %s, got: %s", fi.Name, expected[i], example)
+		}
+		l[fi.Name] = fi.Example()
+	}
+	if err := Load(&myConfig, WithLookuper(l)); err != nil {
+		t.Errorf("expected the examples to load, got: %v", err)
+	}
+}
+
+func TestWriteEnvExample(t *testing.T) {
+	var myConfig struct {
+		DatabaseUrl string `cfg:"required;example=postgres://db.internal/app;usage=connection string of the primary database"`
+		Port        int    `cfg:"default=8080;usage=port to listen on"`
+		ApiKey      string `cfg:"secret;default=dev-key;requiredIn=prod"`
+		Greeting    string `cfg:"example=Hello, \"$USER\" # welcome"`
+		Verbose     bool   `cfg:"deprecated=use LOG_LEVEL"`
+	}
+	expected := `# connection string of the primary database (required)
+DATABASE_URL=postgres://db.internal/app
+# port to listen on
+PORT=8080
+# (required in prod)
+API_KEY=example
+GREETING="Hello, \"\$USER\" # welcome"
+# (deprecated: use LOG_LEVEL)
+VERBOSE=true
+`
+	var out strings.Builder
+	if err := WriteEnvExample(&out, &myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+	vars, err := ParseDotenv(strings.NewReader(out.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Load(&myConfig, WithLookuper(MapLookuper(vars))); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.Greeting != `Hello, "$USER" # welcome` || myConfig.DatabaseUrl != "postgres://db.internal/app" {
+		t.Errorf("expected the examples to be read back, got: %#v", myConfig)
+	}
+}
+
+func TestDescribeUIHints(t *testing.T) {
+	var myConfig struct {
+		Accent  string `cfg:"widget=color;group=Theme;order=2"`
+		Title   string `cfg:"group=Theme;order=1"`
+		Retries int
+	}
+	infos, err := Describe(&myConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag := infos[0].Tag; tag.Widget != "color" || tag.Group != "Theme" || tag.Order != 2 {
+		t.Errorf("unexpected hints for ACCENT: %+v", tag)
+	}
+	if tag := infos[1].Tag; tag.Widget != "" || tag.Group != "Theme" || tag.Order != 1 {
+		t.Errorf("unexpected hints for TITLE: %+v", tag)
+	}
+
+	s, err := DescribeSchema(&myConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := s.Variables[0]; v.Widget != "color" || v.Group != "Theme" || v.Order != 2 {
+		t.Errorf("unexpected hints in the schema of ACCENT: %+v", v)
+	}
+
+	var invalid struct {
+		Title string `cfg:"order=first"`
+	}
+	if _, err := Describe(&invalid); err == nil {
+		t.Error("expected an error for a non-integer order, got nil")
+	}
+}
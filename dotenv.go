@@ -0,0 +1,139 @@
+package parsenv
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// LoadDotenvFiles reads variables from one or more files in dotenv format.
+// Files are read in order, with variables from later files taking precedence
+// over the ones from earlier files, so that
+//
+//	l, err := parsenv.LoadDotenvFiles("base.env", "secret.env")
+//
+// reads the defaults from base.env and overrides them with the values from
+// secret.env. The files can have arbitrary names (.env, .flaskenv,
+// production.env, ...). It is an error if one of them doesn't exist.
+//
+// The result is typically combined with the process environment, which then
+// takes precedence over the files:
+//
+//	err = parsenv.Load(&cfg, parsenv.WithLookuper(parsenv.MultiLookuper(parsenv.EnvLookuper, l)))
+func LoadDotenvFiles(names ...string) (MapLookuper, error) {
+	return loadDotenvFiles(nil, names)
+}
+
+// loadDotenvFiles reads the dotenv files, verifying each with v, if it is
+// not nil.
+func loadDotenvFiles(v Verifier, names []string) (MapLookuper, error) {
+	vars := MapLookuper{}
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			if err := v.Verify(name, data); err != nil {
+				return nil, fmt.Errorf("%s: verification failed: %w", name, err)
+			}
+		}
+		fileVars, err := ParseDotenv(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for key, val := range fileVars {
+			vars[key] = val
+		}
+	}
+	return vars, nil
+}
+
+// ParseDotenv parses variables in dotenv format:
+//
+//	# comments and empty lines are ignored
+//	PLAIN=value # trailing comments are ignored too
+//	export EXPORTED=value
+//	SINGLE='no $escapes or # comments in here'
+//	DOUBLE="supports \"escapes\"\nlike \\n and \\t"
+//
+// If a variable is defined multiple times, the last definition wins.
+func ParseDotenv(r io.Reader) (map[string]string, error) {
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for lineNr := 1; scanner.Scan(); lineNr++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, rawVal, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !isValidName(key) {
+			return vars, fmt.Errorf("line %d: expected KEY=value, got: %s", lineNr, line)
+		}
+		val, err := parseDotenvValue(strings.TrimSpace(rawVal))
+		if err != nil {
+			return vars, fmt.Errorf("line %d: %w", lineNr, err)
+		}
+		vars[key] = val
+	}
+	return vars, scanner.Err()
+}
+
+func parseDotenvValue(rawVal string) (string, error) {
+	if rawVal == "" {
+		return "", nil
+	}
+	switch quote := rawVal[0]; quote {
+	case '\'', '"':
+		end := 1
+		var val strings.Builder
+		for ; end < len(rawVal) && rawVal[end] != quote; end++ {
+			c := rawVal[end]
+			if quote == '"' && c == '\\' && end+1 < len(rawVal) {
+				end++
+				switch c = rawVal[end]; c {
+				case 'n':
+					c = '\n'
+				case 't':
+					c = '\t'
+				case 'r':
+					c = '\r'
+				case '"', '\\', '$':
+				default:
+					val.WriteByte('\\')
+				}
+			}
+			val.WriteByte(c)
+		}
+		if end >= len(rawVal) {
+			return "", fmt.Errorf("unterminated quoted value: %s", rawVal)
+		}
+		if rest := strings.TrimSpace(rawVal[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected characters after quoted value: %s", rest)
+		}
+		return val.String(), nil
+	}
+	if i := strings.Index(rawVal, " #"); i >= 0 {
+		rawVal = rawVal[:i]
+	}
+	return strings.TrimSpace(rawVal), nil
+}
+
+// isValidName reports whether name is a valid variable name, consisting of
+// letters, digits, underscores, and dots, and not starting with a digit.
+func isValidName(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, c := range name {
+		if !(c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
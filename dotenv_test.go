@@ -0,0 +1,69 @@
+package parsenv
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	input := `# database
+DB_HOST=localhost # dev only
+export DB_PORT=5432
+DB_PASSWORD='p#ss $word'
+GREETING="hello\n\"world\""
+EMPTY=
+  SPACED = value with spaces  
+`
+	vars, err := ParseDotenv(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"DB_HOST":     "localhost",
+		"DB_PORT":     "5432",
+		"DB_PASSWORD": "p#ss $word",
+		"GREETING":    "hello\n\"world\"",
+		"EMPTY":       "",
+		"SPACED":      "value with spaces",
+	}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Errorf("expected %#v, got: %#v", expected, vars)
+	}
+}
+
+func TestParseDotenvErrors(t *testing.T) {
+	for _, input := range []string{
+		"NO_EQUALS_SIGN",
+		"1NVALID=name",
+		`UNTERMINATED="value`,
+		`TRAILING="value" garbage`,
+	} {
+		if _, err := ParseDotenv(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected non-nil error, got nil", input)
+		}
+	}
+}
+
+func TestLoadDotenvFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.env")
+	secret := filepath.Join(dir, "secret.env")
+	os.WriteFile(base, []byte("HOST=localhost\nPASSWORD=changeme\n"), 0644)
+	os.WriteFile(secret, []byte("PASSWORD=hunter2\n"), 0600)
+
+	l, err := LoadDotenvFiles(base, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := MapLookuper{"HOST": "localhost", "PASSWORD": "hunter2"}
+	if !reflect.DeepEqual(l, expected) {
+		t.Errorf("expected %#v, got: %#v", expected, l)
+	}
+
+	if _, err := LoadDotenvFiles(base, filepath.Join(dir, "missing.env")); err == nil {
+		t.Error("expected non-nil error, got nil")
+	}
+}
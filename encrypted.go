@@ -0,0 +1,153 @@
+package parsenv
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// encPrefix marks encrypted values, see RegisterDecryptor.
+const encPrefix = "enc:"
+
+// A Decryptor decrypts the ciphertext of encrypted values, see
+// RegisterDecryptor.
+type Decryptor interface {
+	Decrypt(ciphertext string) (plaintext string, err error)
+}
+
+// The DecryptorFunc type is an adapter to allow the use of ordinary
+// functions as Decryptors.
+type DecryptorFunc func(ciphertext string) (string, error)
+
+// Decrypt calls f(ciphertext).
+func (f DecryptorFunc) Decrypt(ciphertext string) (string, error) {
+	return f(ciphertext)
+}
+
+var (
+	decryptorsMu sync.RWMutex
+	decryptors   = map[string]Decryptor{}
+)
+
+// RegisterDecryptor makes the Decryptor d available to decrypt values of
+// the form enc:<name>:<ciphertext>. Such values are decrypted by Load
+// before they are parsed (and are not expanded), so that individual
+// secrets can be stored encrypted in otherwise plain env files:
+//
+//	func init() {
+//		key, _ := hex.DecodeString(os.Getenv("CONFIG_KEY"))
+//		parsenv.RegisterDecryptor("v1", parsenv.AESGCM{Key: key})
+//	}
+//
+//	// DB_PASSWORD=enc:v1:2kV0ayhQ...
+//
+// Decryptors backed by a KMS, or by age, implement Decryptor (or use
+// DecryptorFunc) around the respective client. Registering each key under
+// a new name (v1, v2, ...) lets encrypted values be rotated gradually.
+// Fields holding encrypted values should be tagged `secret`, so that the
+// plaintext isn't reported.
+//
+// It is an error for a value to start with enc: without a Decryptor being
+// registered under the name that follows. Like database/sql.Register,
+// RegisterDecryptor is meant to be called from init functions, and panics
+// if a Decryptor with the same name is already registered, or if d is nil.
+func RegisterDecryptor(name string, d Decryptor) {
+	if name == "" || strings.Contains(name, ":") {
+		panic(fmt.Sprintf("parsenv: RegisterDecryptor with invalid name %q", name))
+	}
+	if d == nil {
+		panic(fmt.Sprintf("parsenv: RegisterDecryptor(%q) with nil Decryptor", name))
+	}
+	decryptorsMu.Lock()
+	defer decryptorsMu.Unlock()
+	if _, dup := decryptors[name]; dup {
+		panic(fmt.Sprintf("parsenv: RegisterDecryptor called twice for %q", name))
+	}
+	decryptors[name] = d
+}
+
+// lookupDecryptor returns the Decryptor registered as name.
+func lookupDecryptor(name string) (Decryptor, bool) {
+	decryptorsMu.RLock()
+	defer decryptorsMu.RUnlock()
+	d, ok := decryptors[name]
+	return d, ok
+}
+
+// decryptValue decrypts val, if it is an encrypted value, and returns it as
+// is otherwise.
+func decryptValue(val string) (string, error) {
+	rest, ok := strings.CutPrefix(val, encPrefix)
+	if !ok {
+		return val, nil
+	}
+	name, ciphertext, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", errors.New("invalid encrypted value: must be enc:<name>:<ciphertext>")
+	}
+	d, ok := lookupDecryptor(name)
+	if !ok {
+		return "", fmt.Errorf("no decryptor registered for encrypted value enc:%s: (register it with RegisterDecryptor)", name)
+	}
+	plaintext, err := d.Decrypt(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt value with decryptor %s: %w", name, err)
+	}
+	return plaintext, nil
+}
+
+// AESGCM is a Decryptor for values encrypted locally with AES-GCM, using a
+// 16, 24, or 32 byte Key. The ciphertext is the base64 (standard encoding)
+// of the nonce followed by the sealed plaintext, as produced by Encrypt.
+type AESGCM struct {
+	Key []byte
+}
+
+func (a AESGCM) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(a.Key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Decrypt decrypts ciphertext produced by Encrypt with the same key.
+func (a AESGCM) Decrypt(ciphertext string) (string, error) {
+	aead, err := a.aead()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	if len(data) < aead.NonceSize() {
+		return "", errors.New("invalid ciphertext: too short")
+	}
+	nonce, sealed := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Encrypt encrypts plaintext, returning the ciphertext to store after
+// enc:<name>: in a value, where name is the name a with the same key is
+// registered under.
+func (a AESGCM) Encrypt(plaintext string) (string, error) {
+	aead, err := a.aead()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
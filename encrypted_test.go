@@ -0,0 +1,68 @@
+package parsenv
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testKey = bytes.Repeat([]byte{0x42}, 32)
+
+func init() {
+	RegisterDecryptor("test-v1", AESGCM{Key: testKey})
+	RegisterDecryptor("test-reverse", DecryptorFunc(func(ciphertext string) (string, error) {
+		r := []rune(ciphertext)
+		for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+			r[i], r[j] = r[j], r[i]
+		}
+		return string(r), nil
+	}))
+}
+
+func TestLoadEncrypted(t *testing.T) {
+	ciphertext, err := AESGCM{Key: testKey}.Encrypt("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var myConfig struct {
+		DbPassword string `cfg:"secret;expand"`
+		Port       int
+		Host       string
+	}
+	t.Setenv("DB_PASSWORD", "enc:test-v1:"+ciphertext)
+	t.Setenv("PORT", "enc:test-reverse:0808")
+	t.Setenv("HOST", "db.example.com")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.DbPassword != "hunter2" || myConfig.Port != 8080 || myConfig.Host != "db.example.com" {
+		t.Errorf("expected the decrypted values, got: %+v", myConfig)
+	}
+
+	for val, expected := range map[string]string{
+		"enc:test-v2:abc":         "no decryptor registered for encrypted value enc:test-v2:",
+		"enc:test-v1":             "must be enc:<name>:<ciphertext>",
+		"enc:test-v1:" + "AAAAAA": "cannot decrypt value with decryptor test-v1",
+	} {
+		t.Setenv("DB_PASSWORD", val)
+		err := Load(&myConfig)
+		var perr *ParseError
+		if !errors.As(err, &perr) || !strings.Contains(perr.Error(), expected) {
+			t.Errorf("expected %q for %s, got: %v", expected, val, err)
+		}
+	}
+}
+
+func TestEncryptedParseErrorHidesPlaintext(t *testing.T) {
+	var myConfig struct {
+		Port int
+	}
+	t.Setenv("PORT", "enc:test-reverse:ytrof")
+	err := Load(&myConfig)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Value != "enc:test-reverse:ytrof" {
+		t.Errorf("expected the error to hold the encrypted value, got: %#v", perr)
+	}
+}
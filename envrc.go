@@ -0,0 +1,107 @@
+package parsenv
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LoadEnvrc reads the variables defined in a direnv .envrc file, so that the
+// values used for local development can also be loaded directly by the
+// application, e.g. in tests:
+//
+//	l, err := parsenv.LoadEnvrc("../.envrc")
+//
+// Only a common subset of .envrc files is supported: everything understood
+// by ParseShellEnv, plus the following direnv functions:
+//
+//	dotenv [path]                 load a dotenv file (default: .env)
+//	dotenv_if_exists [path]       same, but ignore the file if it doesn't exist
+//	source_env path               load another .envrc (or path/.envrc)
+//	source_env_if_exists path     same, but ignore the file if it doesn't exist
+//	watch_file, watch_dir, strict_env, unstrict_env  ignored
+//
+// Relative paths are resolved against the directory of the .envrc file.
+// Any other command is an error.
+func LoadEnvrc(name string) (MapLookuper, error) {
+	return loadEnvrc(name, map[string]bool{})
+}
+
+// loadEnvrc loads the .envrc file name. seen holds the files currently being
+// sourced, to detect recursion.
+func loadEnvrc(name string, seen map[string]bool) (MapLookuper, error) {
+	if fi, err := os.Stat(name); err == nil && fi.IsDir() {
+		name = filepath.Join(name, ".envrc")
+	}
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	if seen[abs] {
+		return nil, fmt.Errorf("%s: sourced recursively", name)
+	}
+	seen[abs] = true
+	defer delete(seen, abs) // only the files being sourced, so that diamonds are fine
+	bs, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(name)
+	resolve := func(path string) string {
+		if filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(dir, path)
+	}
+	merge := func(vars map[string]string, load func() (MapLookuper, error), ifExists bool) error {
+		loaded, err := load()
+		if ifExists && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for key, val := range loaded {
+			vars[key] = val
+		}
+		return nil
+	}
+	dotenv := func(ifExists bool) shellCommand {
+		return func(vars map[string]string, args []string) error {
+			path := ".env"
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return merge(vars, func() (MapLookuper, error) {
+				return LoadDotenvFiles(resolve(path))
+			}, ifExists)
+		}
+	}
+	sourceEnv := func(ifExists bool) shellCommand {
+		return func(vars map[string]string, args []string) error {
+			if len(args) != 1 {
+				return errors.New("source_env: expected exactly one path")
+			}
+			return merge(vars, func() (MapLookuper, error) {
+				return loadEnvrc(resolve(args[0]), seen)
+			}, ifExists)
+		}
+	}
+	ignore := func(map[string]string, []string) error { return nil }
+	vars, err := parseShellEnv(string(bs), nil, map[string]shellCommand{
+		"dotenv":               dotenv(false),
+		"dotenv_if_exists":     dotenv(true),
+		"source_env":           sourceEnv(false),
+		"source_env_if_exists": sourceEnv(true),
+		"watch_file":           ignore,
+		"watch_dir":            ignore,
+		"strict_env":           ignore,
+		"unstrict_env":         ignore,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return vars, nil
+}
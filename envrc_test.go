@@ -0,0 +1,76 @@
+package parsenv
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadEnvrc(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "shared"), 0755)
+	os.WriteFile(filepath.Join(dir, "shared", ".envrc"), []byte("export REGION=eu-central-1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("DB_PASSWORD=hunter2\nDB_HOST=from-dotenv\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".envrc"), []byte(`strict_env
+source_env shared
+dotenv
+dotenv_if_exists .env.local
+export DB_HOST=localhost
+watch_file config.yaml
+`), 0644)
+
+	l, err := LoadEnvrc(filepath.Join(dir, ".envrc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := MapLookuper{
+		"REGION":      "eu-central-1",
+		"DB_PASSWORD": "hunter2",
+		"DB_HOST":     "localhost",
+	}
+	if !reflect.DeepEqual(l, expected) {
+		t.Errorf("expected %#v, got: %#v", expected, l)
+	}
+}
+
+func TestLoadEnvrcErrors(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"unsupported": "use nix\n",
+		"missing":     "dotenv does-not-exist.env\n",
+		"recursive":   "source_env .\n",
+	} {
+		os.Mkdir(filepath.Join(dir, name), 0755)
+		os.WriteFile(filepath.Join(dir, name, ".envrc"), []byte(content), 0644)
+		if _, err := LoadEnvrc(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%s: expected non-nil error, got nil", name)
+		}
+	}
+}
+
+func TestLoadEnvrcDiamond(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"common": "export REGION=eu-central-1\n",
+		"db":     "source_env ../common\nexport DB_HOST=db.internal\n",
+		"cache":  "source_env ../common\nexport CACHE_HOST=cache.internal\n",
+	} {
+		os.Mkdir(filepath.Join(dir, name), 0755)
+		os.WriteFile(filepath.Join(dir, name, ".envrc"), []byte(content), 0644)
+	}
+	os.WriteFile(filepath.Join(dir, ".envrc"), []byte("source_env db\nsource_env cache\n"), 0644)
+
+	l, err := LoadEnvrc(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := MapLookuper{
+		"REGION":     "eu-central-1",
+		"DB_HOST":    "db.internal",
+		"CACHE_HOST": "cache.internal",
+	}
+	if !reflect.DeepEqual(l, expected) {
+		t.Errorf("expected %#v, got: %#v", expected, l)
+	}
+}
@@ -0,0 +1,190 @@
+package parsenv
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A TagError describes a `cfg` struct tag that could not be parsed.
+type TagError struct {
+	Field string // name of the struct field
+	Tag   string // the complete struct tag
+	Err   error
+}
+
+func (e *TagError) Error() string {
+	return fmt.Sprintf("invalid cfg tag on field %s: %v", e.Field, e.Err)
+}
+
+func (e *TagError) Unwrap() error {
+	return e.Err
+}
+
+// A MissingError describes a required field for which no value was found.
+type MissingError struct {
+	Field     string // name of the struct field
+	Name      string // name of the environment variable
+	Owner     string // owner of the field, given with `owner=<owner>`, if any
+	Condition string // the condition given with `requiredIf=<condition>`, if any
+}
+
+func (e *MissingError) Error() string {
+	if e.Condition != "" {
+		return fmt.Sprintf("missing env value for required field: %s (required if %s)%s", e.Field, e.Condition, ownedBy(e.Owner))
+	}
+	return fmt.Sprintf("missing env value for required field: %s%s", e.Field, ownedBy(e.Owner))
+}
+
+// A RecommendedWarning describes a field tagged `recommended` for which no
+// value was found. It is not returned by Load, but passed to Options.Warn.
+type RecommendedWarning struct {
+	Field  string // name of the struct field
+	Name   string // name of the environment variable
+	Reason string // the reason given with `recommended=<reason>`, if any
+	Owner  string // owner of the field, given with `owner=<owner>`, if any
+}
+
+func (e *RecommendedWarning) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("recommended env value for field %s is not set (%s)%s: %s", e.Field, e.Name, ownedBy(e.Owner), e.Reason)
+	}
+	return fmt.Sprintf("recommended env value for field %s is not set (%s)%s", e.Field, e.Name, ownedBy(e.Owner))
+}
+
+// A DeprecatedWarning describes a field tagged `deprecated` whose variable
+// is set. The value is loaded nonetheless. It is not returned by Load, but
+// passed to Options.Warn.
+type DeprecatedWarning struct {
+	Field  string // name of the struct field
+	Name   string // name of the environment variable
+	Reason string // the reason given with `deprecated=<reason>`, if any
+	Owner  string // owner of the field, given with `owner=<owner>`, if any
+}
+
+func (e *DeprecatedWarning) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("env variable %s for field %s is deprecated%s: %s", e.Name, e.Field, ownedBy(e.Owner), e.Reason)
+	}
+	return fmt.Sprintf("env variable %s for field %s is deprecated%s", e.Name, e.Field, ownedBy(e.Owner))
+}
+
+// A StaleWarning describes the value of a field tagged `rotate=<period>`
+// that was last changed longer ago than the period, e.g. a secret that is
+// overdue for rotation. It is not returned by Load, but passed to
+// Options.Warn.
+type StaleWarning struct {
+	Field   string        // name of the struct field
+	Name    string        // name of the environment variable
+	Owner   string        // owner of the field, given with `owner=<owner>`, if any
+	ModTime time.Time     // when the value was last changed
+	Age     time.Duration // how long ago that was, at the time of loading
+	Rotate  time.Duration // the rotation period
+}
+
+func (e *StaleWarning) Error() string {
+	return fmt.Sprintf("value of %s for field %s%s was last changed %s ago, but should be rotated every %s", e.Name, e.Field, ownedBy(e.Owner), formatPeriod(e.Age), formatPeriod(e.Rotate))
+}
+
+// A ParseError describes a value that could not be parsed into the type of
+// its field.
+type ParseError struct {
+	Field string // name of the struct field
+	Name  string // name of the environment variable
+	Value string // the value that failed to parse
+	Owner string // owner of the field, given with `owner=<owner>`, if any
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("cannot parse %s for field %s%s: %v", e.Name, e.Field, ownedBy(e.Owner), e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// A ValidationError describes an error returned by the Validate method of a
+// config struct, or of a struct nested in it.
+type ValidationError struct {
+	Field string // path of the struct field holding the struct, empty for the config struct itself
+	Type  string // type of the struct
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("invalid config %s: %v", e.Type, e.Err)
+	}
+	return fmt.Sprintf("invalid config %s in field %s: %v", e.Type, e.Field, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// A LookupError describes a failure of a Source to retrieve a value.
+type LookupError struct {
+	Field string // name of the struct field
+	Name  string // name of the variable
+	Owner string // owner of the field, given with `owner=<owner>`, if any
+	Err   error
+}
+
+func (e *LookupError) Error() string {
+	return fmt.Sprintf("cannot look up %s for field %s%s: %v", e.Name, e.Field, ownedBy(e.Owner), e.Err)
+}
+
+func (e *LookupError) Unwrap() error {
+	return e.Err
+}
+
+// ownedBy formats the owner of a field for error messages.
+func ownedBy(owner string) string {
+	if owner == "" {
+		return ""
+	}
+	return " (owned by " + owner + ")"
+}
+
+// A LoadError collects all errors that occurred while loading a struct.
+// Use errors.As or errors.Is to look for specific errors, or Errs to get all
+// of them.
+type LoadError struct {
+	// Errs contains all errors, even if Options.MaxErrors limits how many of
+	// them are included in the error message.
+	Errs []error
+
+	maxErrors int
+}
+
+func newLoadError(errs []error, opts Options) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &LoadError{Errs: errs, maxErrors: opts.MaxErrors}
+}
+
+// Error returns the messages of the errors, one per line. If there are more
+// errors than Options.MaxErrors allows, the remaining ones are summarized.
+func (e *LoadError) Error() string {
+	errs := e.Errs
+	if e.maxErrors > 0 && len(errs) > e.maxErrors {
+		errs = errs[:e.maxErrors]
+	}
+	var msg strings.Builder
+	for i, err := range errs {
+		if i > 0 {
+			msg.WriteByte('\n')
+		}
+		msg.WriteString(err.Error())
+	}
+	if more := len(e.Errs) - len(errs); more > 0 {
+		fmt.Fprintf(&msg, "\n...and %d more", more)
+	}
+	return msg.String()
+}
+
+func (e *LoadError) Unwrap() []error {
+	return e.Errs
+}
@@ -0,0 +1,77 @@
+package parsenv
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiFaint  = "\x1b[2m"
+)
+
+// FormatError renders err in a human-friendly way, grouping the problems by
+// their kind (missing required variables, values that failed to parse,
+// invalid struct tags, ...) and aligning them in columns.
+// If isTTY is true, the output is colorized using ANSI escape sequences.
+//
+//	if err := parsenv.Load(&cfg); err != nil {
+//		fmt.Fprint(os.Stderr, parsenv.FormatError(err, isTerminal(os.Stderr)))
+//		os.Exit(1)
+//	}
+func FormatError(err error, isTTY bool) string {
+	groups := []struct {
+		title string
+		errs  []error
+	}{
+		{title: "Missing required variables"},
+		{title: "Invalid values"},
+		{title: "Invalid struct tags"},
+		{title: "Other problems"},
+	}
+	for _, err := range flattenErrors(err) {
+		switch err.(type) {
+		case *MissingError:
+			groups[0].errs = append(groups[0].errs, err)
+		case *ParseError, *ValidationError:
+			groups[1].errs = append(groups[1].errs, err)
+		case *TagError:
+			groups[2].errs = append(groups[2].errs, err)
+		default:
+			groups[3].errs = append(groups[3].errs, err)
+		}
+	}
+
+	style := func(code, s string) string {
+		if !isTTY || s == "" {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	var out strings.Builder
+	for _, group := range groups {
+		if len(group.errs) == 0 {
+			continue
+		}
+		if out.Len() > 0 {
+			out.WriteByte('\n')
+		}
+		fmt.Fprintf(&out, "%s (%d):\n", style(ansiBold+ansiRed, group.title), len(group.errs))
+		tw := tabwriter.NewWriter(&out, 0, 4, 2, ' ', 0)
+		for _, err := range group.errs {
+			d := errorDetail(err)
+			name := d.EnvVar
+			if name == "" {
+				name = d.Field
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", style(ansiYellow, name), d.Reason, style(ansiFaint, d.Hint))
+		}
+		tw.Flush()
+	}
+	return out.String()
+}
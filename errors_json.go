@@ -0,0 +1,112 @@
+package parsenv
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// An ErrorDetail describes a single problem found by Load in a form suitable
+// for machine consumption.
+type ErrorDetail struct {
+	Field  string `json:"field,omitempty"`  // name of the struct field
+	EnvVar string `json:"envVar,omitempty"` // name of the environment variable
+	Reason string `json:"reason"`           // what went wrong
+	Hint   string `json:"hint,omitempty"`   // how to fix it
+	Owner  string `json:"owner,omitempty"`  // owner of the field, given with `owner=<owner>`
+}
+
+// ErrorDetails splits err into the individual problems it consists of.
+// Errors that wrap multiple errors (like *LoadError, or the result of
+// errors.Join) are flattened.
+func ErrorDetails(err error) []ErrorDetail {
+	var details []ErrorDetail
+	for _, err := range flattenErrors(err) {
+		details = append(details, errorDetail(err))
+	}
+	return details
+}
+
+func errorDetail(err error) ErrorDetail {
+	d := errorDetailOf(err)
+	if d.Owner != "" {
+		d.Hint += ", or ask " + d.Owner
+	}
+	return d
+}
+
+func errorDetailOf(err error) ErrorDetail {
+	switch err := err.(type) {
+	case *TagError:
+		return ErrorDetail{
+			Field:  err.Field,
+			Reason: err.Err.Error(),
+			Hint:   fmt.Sprintf("fix the cfg tag of field %s", err.Field),
+		}
+	case *MissingError:
+		d := ErrorDetail{
+			Field:  err.Field,
+			EnvVar: err.Name,
+			Reason: "missing value for required field",
+			Hint:   fmt.Sprintf("set the environment variable %s", err.Name),
+			Owner:  err.Owner,
+		}
+		if err.Condition != "" {
+			d.Reason += " (required if " + err.Condition + ")"
+		}
+		return d
+	case *ParseError:
+		return ErrorDetail{
+			Field:  err.Field,
+			EnvVar: err.Name,
+			Reason: err.Err.Error(),
+			Hint:   fmt.Sprintf("check the value of %s", err.Name),
+			Owner:  err.Owner,
+		}
+	case *ValidationError:
+		return ErrorDetail{
+			Field:  err.Field,
+			Reason: err.Err.Error(),
+			Hint:   fmt.Sprintf("check the variables validated by %s.Validate", err.Type),
+		}
+	case *LookupError:
+		return ErrorDetail{
+			Field:  err.Field,
+			EnvVar: err.Name,
+			Reason: err.Err.Error(),
+			Hint:   "check that the configuration source is reachable",
+			Owner:  err.Owner,
+		}
+	}
+	return ErrorDetail{Reason: err.Error()}
+}
+
+// flattenErrors recursively unwraps errors that wrap multiple errors.
+func flattenErrors(err error) (errs []error) {
+	if werr, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range werr.Unwrap() {
+			errs = append(errs, flattenErrors(err)...)
+		}
+		return errs
+	}
+	if err == nil {
+		return nil
+	}
+	return []error{err}
+}
+
+// ErrorJSON renders err as a JSON array of ErrorDetail objects, so that
+// platforms capturing the output of a service can display configuration
+// failures in a structured way.
+//
+//	if err := parsenv.Load(&cfg); err != nil {
+//		out, _ := parsenv.ErrorJSON(err)
+//		os.Stderr.Write(out)
+//		os.Exit(1)
+//	}
+func ErrorJSON(err error) ([]byte, error) {
+	details := ErrorDetails(err)
+	if details == nil {
+		details = []ErrorDetail{}
+	}
+	return json.Marshal(details)
+}
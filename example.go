@@ -0,0 +1,170 @@
+package parsenv
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// WriteEnvExample writes a .env.example template for cfg to w, in dotenv
+// format (see ParseDotenv). Each variable is set to its example value (see
+// FieldInfo.Example), after a comment with its description and whether it
+// is required:
+//
+//	# connection string of the primary database (required)
+//	DATABASE_URL=postgres://db.internal/app
+//	# port to listen on
+//	PORT=8080
+//
+// Like Describe, it returns problems with the struct definition as a
+// *LoadError.
+func WriteEnvExample(w io.Writer, cfg any, opts ...Option) error {
+	infos, err := Describe(cfg, opts...)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	for _, fi := range infos {
+		comment := fi.Tag.Usage
+		switch {
+		case fi.Tag.Required:
+			comment += " (required)"
+		case len(fi.Tag.RequiredIn) > 0:
+			comment += " (required in " + strings.Join(fi.Tag.RequiredIn, ",") + ")"
+		case fi.Tag.RequiredIf != "":
+			comment += " (required if " + requiredIfCondition(fi.Tag) + ")"
+		}
+		if fi.Tag.Deprecated {
+			comment += " (deprecated" + prefixed(": ", fi.Tag.DeprecatedReason) + ")"
+		}
+		if comment = strings.TrimSpace(comment); comment != "" {
+			fmt.Fprintf(&sb, "# %s\n", strings.ReplaceAll(comment, "\n", "\n# "))
+		}
+		fmt.Fprintf(&sb, "%s=%s\n", fi.Name, dotenvQuote(fi.Example()))
+	}
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// dotenvQuote double quotes val if ParseDotenv wouldn't read it back as is.
+func dotenvQuote(val string) string {
+	if !strings.ContainsAny(val, " \t\r\n#'\"\\$") {
+		return val
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + r.Replace(val) + `"`
+}
+
+// Example returns a plausible value for the variable, for templates like
+// .env.example files and documentation. That is the value given with the
+// `example` property of the `cfg` tag, the default value (unless the field is
+// secret, or the default is a relative time like now+1h), or else a value
+// synthesized from the field's type and tag, which Load would accept:
+//
+//	Port     int       // 1
+//	Workers  int       `cfg:"min=2;max=64"` // 2
+//	Debug    bool      // true
+//	Hosts    []string  // example,example
+//	Deadline time.Time `cfg:"layout=2006-01-02"` // 2006-01-02
+//	Currency string    `cfg:"pattern=^[A-Z]{3}$"` // AAA
+func (fi FieldInfo) Example() string {
+	if fi.Tag.Example != "" {
+		return fi.Tag.Example
+	}
+	if _, relative, _ := relativeTime(fi.Type, fi.Tag.Default, time.Time{}); fi.Tag.Default != "" && !fi.Tag.Secret && !relative {
+		return fi.Tag.Default
+	}
+	return exampleValue(fi.Type, fi.Tag)
+}
+
+// exampleValue synthesizes a value of type t.
+func exampleValue(t reflect.Type, td TagData) string {
+	if td.JSON {
+		return exampleJSON(t)
+	}
+	if len(td.OneOf) > 0 && t.Kind() != reflect.Pointer && t.Kind() != reflect.Slice {
+		return td.OneOf[0]
+	}
+	if bound := cmp.Or(td.Min, td.Max); bound != "" && isUnitKind(t.Kind()) {
+		return bound
+	}
+	if us, ok := lookupUnits(td.Unit); ok && isUnitKind(t.Kind()) {
+		return us.example()
+	}
+	if t == timeType {
+		return time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC).Format(timeLayout(td))
+	}
+	switch t {
+	case reflect.TypeFor[netip.Addr]():
+		return "192.0.2.1"
+	case reflect.TypeFor[netip.AddrPort]():
+		return "192.0.2.1:8080"
+	case reflect.TypeFor[netip.Prefix]():
+		return "192.0.2.0/24"
+	case reflect.TypeFor[regexp.Regexp]():
+		return "^example$"
+	case reflect.TypeFor[big.Int]():
+		return "123456789012345678901234567890"
+	case bigFloatType:
+		return "1234567890.123456789"
+	case reflect.TypeFor[TimeWindow]():
+		return "22:00-06:00 Europe/Zurich"
+	case slogLevelType, slogLevelVarType:
+		return "info"
+	case keyValueType:
+		return "example" + mapKVSep(td) + "example"
+	case durationType:
+		return "30s"
+	case fileModeType:
+		return "0640"
+	case locationType:
+		return "Europe/Zurich"
+	case reflect.TypeFor[WeightedItem]():
+		return "a.example.com:3"
+	case reflect.TypeFor[ByteSize]():
+		return "512MiB"
+	case reflect.TypeFor[Rate]():
+		return "100/s"
+	}
+	if t == urlType {
+		scheme := "https"
+		if len(td.Schemes) > 0 {
+			scheme = td.Schemes[0]
+		}
+		return scheme + "://example.com"
+	}
+	switch t.Kind() {
+	default:
+		return ""
+	case reflect.String:
+		if td.pattern != nil && !td.pattern.MatchString("example") {
+			example, _ := patternExample(td.pattern)
+			return example
+		}
+		return "example"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "1"
+	case reflect.Float32, reflect.Float64:
+		return "0.5"
+	case reflect.Bool:
+		return "true"
+	case reflect.Pointer:
+		return exampleValue(t.Elem(), td)
+	case reflect.Slice:
+		if isBytes(t) {
+			example, _ := encodeBytes([]byte("example"), td)
+			return example
+		}
+		elem := exampleValue(t.Elem(), td)
+		return elem + sliceSep(td) + elem
+	case reflect.Map:
+		return exampleValue(t.Key(), td) + mapKVSep(td) + exampleValue(t.Elem(), td)
+	}
+}
@@ -0,0 +1,49 @@
+package parsenv
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// expand replaces references to variables in s with the values returned by
+// lookup. References are written as $VAR or ${VAR}, on Windows additionally as
+// %VAR%. Like in cmd.exe, %VAR% references to undefined variables are left
+// untouched.
+//
+// On Windows, variable names are case-insensitive, so %Path% and %PATH% refer
+// to the same variable.
+func expand(s string, lookup func(string) (string, bool)) string {
+	if runtime.GOOS == "windows" {
+		s = expandPercent(s, lookup)
+	}
+	return os.Expand(s, func(name string) string {
+		val, _ := lookup(name)
+		return val
+	})
+}
+
+func expandPercent(s string, lookup func(string) (string, bool)) string {
+	var expanded strings.Builder
+	for {
+		start := strings.IndexByte(s, '%')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(s[start+1:], '%')
+		if end < 0 {
+			break
+		}
+		end += start + 1
+		expanded.WriteString(s[:start])
+		if val, ok := lookup(s[start+1 : end]); ok && end > start+1 {
+			expanded.WriteString(val)
+			s = s[end+1:]
+		} else {
+			expanded.WriteByte('%')
+			s = s[start+1:]
+		}
+	}
+	expanded.WriteString(s)
+	return expanded.String()
+}
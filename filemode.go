@@ -0,0 +1,50 @@
+package parsenv
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// fileModeType is parsed as an octal number, like chmod does, rather than as
+// a decimal integer.
+var fileModeType = reflect.TypeFor[fs.FileMode]()
+
+// parseFileMode parses the Unix permission bits val, like 0640 or 2775, into
+// a fs.FileMode. The setuid, setgid, and sticky bits are translated into
+// their fs.FileMode counterparts.
+func parseFileMode(val string) (fs.FileMode, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(val, "0o"), "0O")
+	bits, err := strconv.ParseUint(digits, 8, 32)
+	if err != nil || bits > 0o7777 {
+		return 0, fmt.Errorf("invalid file mode %q: must be octal permission bits like 0640", val)
+	}
+	mode := fs.FileMode(bits) & fs.ModePerm
+	if bits&0o4000 != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if bits&0o2000 != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if bits&0o1000 != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode, nil
+}
+
+// formatFileMode formats mode like parseFileMode expects it.
+func formatFileMode(mode fs.FileMode) string {
+	bits := uint32(mode.Perm())
+	if mode&fs.ModeSetuid != 0 {
+		bits |= 0o4000
+	}
+	if mode&fs.ModeSetgid != 0 {
+		bits |= 0o2000
+	}
+	if mode&fs.ModeSticky != 0 {
+		bits |= 0o1000
+	}
+	return fmt.Sprintf("%04o", bits)
+}
@@ -0,0 +1,64 @@
+package parsenv
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// fileSuffix is appended to the name of a variable to get the name of the
+// variable holding the path of a file containing the value, see
+// Options.FileVariables.
+const fileSuffix = "_FILE"
+
+// lookupFileVariable reads the value of the variable name from the file
+// named by NAME_FILE, if name itself is unset (or empty). val and ok are the
+// result of looking up name.
+func lookupFileVariable(name, val string, ok bool, opts Options) (string, bool, error) {
+	path, fileOK, err := opts.lookupErr(name + fileSuffix)
+	if err != nil || !fileOK || path == "" {
+		return val, ok, err
+	}
+	if val != "" {
+		return "", false, fmt.Errorf("both %s and %s%s are set, only one of them may be", name, name, fileSuffix)
+	}
+	val, err = readValueFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("%s%s: %w", name, fileSuffix, err)
+	}
+	return val, true, nil
+}
+
+// lookupFieldFile reads the value of a field tagged `file=<path>` from the
+// file at path, or at the path given by the variable name, if it is set.
+// A missing file at the path of the tag is treated like an unset variable.
+func lookupFieldFile(spec fieldSpec, opts Options) (string, bool, error) {
+	path, ok, err := opts.lookupErr(spec.name)
+	if err != nil {
+		return "", false, err
+	}
+	if !ok || path == "" {
+		path = spec.tag.File
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return "", false, nil
+		}
+	}
+	val, err := readValueFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	return val, true, nil
+}
+
+// readValueFile returns the contents of the file at path, without a single
+// trailing newline.
+func readValueFile(path string) (string, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	s := strings.TrimSuffix(string(bs), "\n")
+	return strings.TrimSuffix(s, "\r"), nil
+}
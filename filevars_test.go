@@ -0,0 +1,91 @@
+package parsenv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFileVariables(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "db_password")
+	if err := os.WriteFile(passwordFile, []byte("hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var myConfig struct {
+		DbPassword string `cfg:"required;secret"`
+		ApiKey     string `cfg:"default=none"`
+		LogFile    string
+	}
+	t.Setenv("DB_PASSWORD_FILE", passwordFile)
+	t.Setenv("API_KEY_FILE", "")
+	t.Setenv("LOG_FILE", "/var/log/app.log")
+
+	if err := Load(&myConfig, WithFileVariables()); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.DbPassword != "hunter2" {
+		t.Errorf("expected hunter2, got: %q", myConfig.DbPassword)
+	}
+	if myConfig.ApiKey != "none" {
+		t.Errorf("expected none, got: %s", myConfig.ApiKey)
+	}
+	if myConfig.LogFile != "/var/log/app.log" {
+		t.Errorf("expected /var/log/app.log, got: %s", myConfig.LogFile)
+	}
+
+	if err := Load(&myConfig); !errors.As(err, new(*MissingError)) {
+		t.Errorf("expected a *MissingError without FileVariables, got: %v", err)
+	}
+
+	t.Setenv("DB_PASSWORD", "hunter3")
+	err := Load(&myConfig, WithFileVariables())
+	var lerr *LookupError
+	if !errors.As(err, &lerr) || lerr.Name != "DB_PASSWORD" {
+		t.Errorf("expected a *LookupError for DB_PASSWORD set twice, got: %v", err)
+	}
+
+	t.Setenv("DB_PASSWORD", "")
+	t.Setenv("DB_PASSWORD_FILE", filepath.Join(dir, "missing"))
+	err = Load(&myConfig, WithFileVariables())
+	if !errors.As(err, &lerr) || lerr.Name != "DB_PASSWORD" || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a *LookupError for the missing file, got: %v", err)
+	}
+}
+
+func TestLoadFileProperty(t *testing.T) {
+	var myConfig struct {
+		DbPassword string `cfg:"required;secret;file=testdata/db_password"`
+		ApiKey     string `cfg:"file=testdata/missing;default=dev"`
+	}
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.DbPassword != "hunter2" {
+		t.Errorf("expected hunter2, got: %q", myConfig.DbPassword)
+	}
+	if myConfig.ApiKey != "dev" {
+		t.Errorf("expected dev, got: %s", myConfig.ApiKey)
+	}
+
+	other := filepath.Join(t.TempDir(), "db_password")
+	if err := os.WriteFile(other, []byte("hunter3"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DB_PASSWORD", other)
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.DbPassword != "hunter3" {
+		t.Errorf("expected hunter3, got: %q", myConfig.DbPassword)
+	}
+
+	t.Setenv("API_KEY", filepath.Join(t.TempDir(), "missing"))
+	err := Load(&myConfig)
+	var lerr *LookupError
+	if !errors.As(err, &lerr) || lerr.Name != "API_KEY" || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a *LookupError for the missing file, got: %v", err)
+	}
+}
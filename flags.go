@@ -0,0 +1,110 @@
+package parsenv
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// A FlagProvider evaluates feature flags. It is the bridge to feature flag
+// systems such as OpenFeature or LaunchDarkly, which parsenv doesn't depend
+// on: implement it with a thin adapter around their client.
+//
+//	type openFeatureFlags struct{ client *openfeature.Client }
+//
+//	func (f openFeatureFlags) Flag(ctx context.Context, key string, t reflect.Type) (any, bool, error) {
+//		evalCtx := openfeature.EvaluationContext{}
+//		switch t.Kind() {
+//		case reflect.Bool:
+//			v, err := f.client.BooleanValue(ctx, key, false, evalCtx)
+//			return v, err == nil, nil // fall back to the environment
+//		case reflect.Float64:
+//			v, err := f.client.FloatValue(ctx, key, 0, evalCtx)
+//			return v, err == nil, nil
+//		default:
+//			v, err := f.client.StringValue(ctx, key, "", evalCtx)
+//			return v, err == nil, nil
+//		}
+//	}
+type FlagProvider interface {
+	// Flag evaluates the flag key for a field of type t. The value is
+	// formatted with fmt.Sprint and then parsed like an environment
+	// variable, so it may be of any type that formats accordingly, e.g. a
+	// bool for a bool field, or a float64 for a percentage.
+	// If the provider doesn't know the flag, ok is false.
+	Flag(ctx context.Context, key string, t reflect.Type) (value any, ok bool, err error)
+}
+
+// flagKey returns the key of the flag a field tagged with `flag` is resolved
+// from: either the key given with `flag=<key>`, or the name of the
+// environment variable in kebab-case (NEW_CHECKOUT becomes new-checkout).
+func flagKey(spec fieldSpec) string {
+	if spec.tag.FlagKey != "" {
+		return spec.tag.FlagKey
+	}
+	return strings.ReplaceAll(strings.ToLower(spec.name), "_", "-")
+}
+
+// lookupField looks up the value of a field, resolving fields tagged with
+// `flag` from Options.FlagProvider first, and falling back to the
+// environment for flags the provider doesn't know. If the variable is unset
+// or empty, the aliases given with `alias` are looked up in order.
+// Fields tagged `secret` are looked up with Options.Secrets, if set.
+// fromEnv reports whether the value was looked up in the process
+// environment.
+func lookupField(spec fieldSpec, opts Options) (val string, found, fromEnv bool, err error) {
+	opts = opts.forField(spec)
+	if spec.tag.Flag && opts.FlagProvider != nil {
+		val, ok, err := opts.FlagProvider.Flag(context.Background(), flagKey(spec), spec.field.Type)
+		if err != nil {
+			return "", false, false, fmt.Errorf("flag %s: %w", flagKey(spec), err)
+		}
+		if ok {
+			return fmt.Sprint(val), true, false, nil
+		}
+	}
+	fromEnv = opts.Lookuper == nil
+	if spec.tag.File != "" {
+		val, found, err = lookupFieldFile(spec, opts)
+		return val, found, fromEnv, err
+	}
+	val, found, err = lookupVariable(spec.name, opts)
+	for _, alias := range spec.tag.Alias {
+		if err != nil || val != "" {
+			break
+		}
+		var aliasOK bool
+		val, aliasOK, err = lookupVariable(alias, opts)
+		found = found || aliasOK
+	}
+	return val, found, fromEnv, err
+}
+
+// lookupVariable looks up the value of the variable name, or reads it from
+// the file named by name_FILE, if Options.FileVariables is set.
+func lookupVariable(name string, opts Options) (string, bool, error) {
+	val, ok, err := opts.lookupErr(name)
+	if err != nil || !opts.FileVariables {
+		return val, ok, err
+	}
+	return lookupFileVariable(name, val, ok, opts)
+}
+
+// unsetVariables removes the variable of a field tagged `unset`, and its
+// aliases, from the process environment, so that child processes don't
+// inherit the value. It is only called for values that were looked up in the
+// process environment; values from Options.Lookuper, Options.Secrets, or
+// Options.FlagProvider leave the environment alone.
+func unsetVariables(spec fieldSpec) error {
+	for _, name := range append([]string{spec.name}, spec.tag.Alias...) {
+		if _, ok := os.LookupEnv(name); !ok {
+			continue
+		}
+		if err := os.Unsetenv(name); err != nil {
+			return fmt.Errorf("cannot unset %s for field %s: %w", name, spec.path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package parsenv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+type fakeFlags map[string]any
+
+func (f fakeFlags) Flag(ctx context.Context, key string, t reflect.Type) (any, bool, error) {
+	if key == "broken" {
+		return nil, false, errors.New("provider not ready")
+	}
+	val, ok := f[key]
+	return val, ok, nil
+}
+
+func TestLoadFlagsUnset(t *testing.T) {
+	var myConfig struct {
+		ApiKey string `cfg:"flag;unset"`
+	}
+	t.Setenv("API_KEY", "hunter2")
+	if err := Load(&myConfig, WithFlagProvider(fakeFlags{"api-key": "hunter3"})); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.ApiKey != "hunter3" || os.Getenv("API_KEY") != "hunter2" {
+		t.Errorf("expected a value from the flag provider to leave API_KEY alone, got: %s", myConfig.ApiKey)
+	}
+}
+
+func TestLoadFlags(t *testing.T) {
+	var myConfig struct {
+		NewCheckout bool    `cfg:"flag"`
+		Rollout     float64 `cfg:"flag=checkout-rollout"`
+		DarkMode    bool    `cfg:"flag;default=yes"`
+		Theme       string
+	}
+	t.Setenv("NEW_CHECKOUT", "no")
+	t.Setenv("THEME", "light")
+	flags := fakeFlags{"new-checkout": true, "checkout-rollout": 0.25, "theme": "dark"}
+
+	if err := Load(&myConfig, WithFlagProvider(flags)); err != nil {
+		t.Fatal(err)
+	}
+	if !myConfig.NewCheckout {
+		t.Error("expected NewCheckout to be resolved from the flag provider")
+	}
+	if myConfig.Rollout != 0.25 {
+		t.Errorf("expected 0.25, got: %f", myConfig.Rollout)
+	}
+	if !myConfig.DarkMode {
+		t.Error("expected DarkMode to fall back to its default")
+	}
+	if myConfig.Theme != "light" {
+		t.Errorf("expected fields without flag to ignore the provider, got: %s", myConfig.Theme)
+	}
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.NewCheckout {
+		t.Error("expected NewCheckout to be read from the environment without a provider")
+	}
+
+	var broken struct {
+		Broken bool `cfg:"flag"`
+	}
+	err := Load(&broken, WithFlagProvider(flags))
+	var lerr *LookupError
+	if !errors.As(err, &lerr) || lerr.Name != "BROKEN" {
+		t.Errorf("expected a *LookupError for BROKEN, got: %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package parsenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// decodeJSON unmarshals the JSON document val into a new value of type t,
+// for fields tagged `json`. val must hold a single document, apart from
+// surrounding whitespace. If strict is set, objects must not contain keys
+// that don't match a field.
+func decodeJSON(t reflect.Type, val string, strict bool) (any, error) {
+	ptr := reflect.New(t)
+	dec := json.NewDecoder(bytes.NewReader([]byte(val)))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(ptr.Interface()); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the JSON document at offset %d", dec.InputOffset())
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// exampleJSON synthesizes a JSON document for a field of type t tagged
+// `json`.
+func exampleJSON(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return "[]"
+	case reflect.Map:
+		return "{}"
+	}
+	doc, err := json.Marshal(reflect.New(t).Elem().Interface())
+	if err != nil {
+		return ""
+	}
+	return string(doc)
+}
@@ -0,0 +1,129 @@
+package parsenv
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLoadJSON(t *testing.T) {
+	type Upstream struct {
+		Host   string `json:"host"`
+		Weight int    `json:"weight"`
+	}
+	var myConfig struct {
+		Upstreams []Upstream     `cfg:"json"`
+		Limits    map[string]int `cfg:"json;default={\"default\":10}"`
+		Primary   Upstream       `cfg:"json"`
+		Extra     any            `cfg:"json"`
+	}
+	t.Setenv("UPSTREAMS", `[{"host":"a.example.com","weight":2},{"host":"b.example.com","weight":1}]`)
+	t.Setenv("PRIMARY", `{"host":"a.example.com","weight":2,"unknown":true}`)
+	t.Setenv("EXTRA", `[1,"two"]`)
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(myConfig.Upstreams, []Upstream{{"a.example.com", 2}, {"b.example.com", 1}}) {
+		t.Errorf("unexpected UPSTREAMS: %v", myConfig.Upstreams)
+	}
+	if len(myConfig.Limits) != 1 || myConfig.Limits["default"] != 10 {
+		t.Errorf("unexpected LIMITS: %v", myConfig.Limits)
+	}
+	if myConfig.Primary != (Upstream{"a.example.com", 2}) {
+		t.Errorf("unexpected PRIMARY: %v", myConfig.Primary)
+	}
+	if extra, ok := myConfig.Extra.([]any); !ok || len(extra) != 2 {
+		t.Errorf("unexpected EXTRA: %v", myConfig.Extra)
+	}
+
+	env, err := CommandEnv(&myConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(env, `PRIMARY={"host":"a.example.com","weight":2}`) {
+		t.Errorf("expected PRIMARY to be formatted as JSON, got: %v", env)
+	}
+
+	t.Setenv("UPSTREAMS", `[{"host":`)
+	err = Load(&myConfig)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Name != "UPSTREAMS" {
+		t.Errorf("expected a *ParseError for UPSTREAMS, got: %v", err)
+	}
+}
+
+func TestLoadJSONStrict(t *testing.T) {
+	var myConfig struct {
+		Primary struct {
+			Host string `json:"host"`
+		} `cfg:"json;strict"`
+	}
+	t.Setenv("PRIMARY", `{"host":"a.example.com","hots":"b.example.com"}`)
+
+	err := Load(&myConfig)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Name != "PRIMARY" {
+		t.Errorf("expected a *ParseError for PRIMARY, got: %v", err)
+	}
+}
+
+func TestLoadJSONLint(t *testing.T) {
+	var myConfig struct {
+		Hosts []string `cfg:"json;sep=|"`
+	}
+	err := Load(&myConfig)
+	var terr *TagError
+	if !errors.As(err, &terr) || terr.Field != "Hosts" {
+		t.Errorf("expected a *TagError for Hosts, got: %v", err)
+	}
+}
+
+type Inner struct {
+	A string `json:"a"`
+	B int    `json:"b"`
+}
+
+func TestLoadJSONEmbedded(t *testing.T) {
+	var myConfig struct {
+		Inner `cfg:"json;name=INNER"`
+	}
+	l := MapLookuper{"INNER": `{"a":"from json","b":2}`, "A": "from env"}
+
+	infos, err := Describe(&myConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 || infos[0].Name != "INNER" {
+		t.Errorf("expected only INNER to be described, got: %+v", infos)
+	}
+	if err := Load(&myConfig, WithLookuper(l)); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.A != "from json" || myConfig.B != 2 {
+		t.Errorf("expected the fields to be decoded from INNER only, got: %+v", myConfig.Inner)
+	}
+}
+
+func TestLoadJSONTrailingData(t *testing.T) {
+	for _, val := range []string{`{"host":"a.example.com"} garbage`, `{"host":"a.example.com"}{}`, `{"host":"a.example.com"} }`} {
+		var myConfig struct {
+			Primary struct {
+				Host string `json:"host"`
+			} `cfg:"json"`
+		}
+		err := Load(&myConfig, WithLookuper(MapLookuper{"PRIMARY": val}))
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Name != "PRIMARY" {
+			t.Errorf("%s: expected a *ParseError for PRIMARY, got: %v", val, err)
+		}
+	}
+	var myConfig struct {
+		Primary struct {
+			Host string `json:"host"`
+		} `cfg:"json"`
+	}
+	if err := Load(&myConfig, WithLookuper(MapLookuper{"PRIMARY": " {\"host\":\"a.example.com\"}\n"})); err != nil {
+		t.Errorf("expected surrounding whitespace to be accepted, got: %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package parsenv
+
+import (
+	"reflect"
+	"strings"
+)
+
+// KeyValue is a key with an optional value. Unlike a map, a []KeyValue field
+// keeps the order of its pairs (and duplicate keys), for values where the
+// order matters, like a middleware chain:
+//
+//	var cfg struct {
+//		Middleware []parsenv.KeyValue `cfg:"kvsep=:;default=auth,logging,tracing:sample=0.1"`
+//	}
+//	// [{auth } {logging } {tracing sample=0.1}]
+//
+// Pairs are separated like the elements of slices, and keys and values like
+// the pairs of maps (see TagData). A pair without a key/value separator has
+// an empty Value.
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+var keyValueType = reflect.TypeFor[KeyValue]()
+
+func parseKeyValue(val string, td TagData) KeyValue {
+	key, value, _ := strings.Cut(val, mapKVSep(td))
+	return KeyValue{Key: trimElem(key, td), Value: trimElem(value, td)}
+}
+
+func formatKeyValue(kv KeyValue, td TagData) string {
+	if kv.Value == "" {
+		return kv.Key
+	}
+	return kv.Key + mapKVSep(td) + kv.Value
+}
@@ -0,0 +1,41 @@
+package parsenv
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLoadKeyValue(t *testing.T) {
+	var myConfig struct {
+		Middleware []KeyValue `cfg:"kvsep=:;default=auth,logging,tracing:sample=0.1"`
+		Headers    []KeyValue `cfg:"sep=|"`
+		Primary    KeyValue
+	}
+	t.Setenv("HEADERS", "X-B=2| X-A=1|X-B=3")
+	t.Setenv("PRIMARY", "db=postgres")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	expected := []KeyValue{{"auth", ""}, {"logging", ""}, {"tracing", "sample=0.1"}}
+	if !slices.Equal(myConfig.Middleware, expected) {
+		t.Errorf("expected %v, got: %v", expected, myConfig.Middleware)
+	}
+	expected = []KeyValue{{"X-B", "2"}, {"X-A", "1"}, {"X-B", "3"}}
+	if !slices.Equal(myConfig.Headers, expected) {
+		t.Errorf("expected %v, got: %v", expected, myConfig.Headers)
+	}
+	if myConfig.Primary != (KeyValue{"db", "postgres"}) {
+		t.Errorf("expected {db postgres}, got: %v", myConfig.Primary)
+	}
+
+	env, err := CommandEnv(&myConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"MIDDLEWARE=auth,logging,tracing:sample=0.1", "HEADERS=X-B=2|X-A=1|X-B=3", "PRIMARY=db=postgres"} {
+		if !slices.Contains(env, expected) {
+			t.Errorf("expected %s, got: %v", expected, env)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package parsenv
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// A DefaultFunc computes the default value of a field, see RegisterDefault.
+// The value must have the type of the field, or the same underlying type.
+type DefaultFunc func() (any, error)
+
+var (
+	defaultsMu sync.RWMutex
+	defaults   = map[string]DefaultFunc{}
+)
+
+// RegisterDefault makes the DefaultFunc fn available to fields tagged
+// `defaultFunc=<name>`, for defaults that are expensive to compute or must
+// be unique, like generated keys or temporary directories:
+//
+//	func init() {
+//		parsenv.RegisterDefault("tempdir", func() (any, error) {
+//			return os.MkdirTemp("", "app")
+//		})
+//	}
+//
+//	var cfg struct {
+//		CacheDir parsenv.Lazy[string] `cfg:"defaultFunc=tempdir"`
+//		WorkDir  string               `cfg:"defaultFunc=tempdir"`
+//	}
+//
+// fn is only called if the variable is unset: by Load for ordinary fields,
+// and not before the first call to Get for Lazy fields.
+// Like database/sql.Register, RegisterDefault is meant to be called from
+// init functions, and panics if a DefaultFunc with the same name is already
+// registered, or if fn is nil.
+func RegisterDefault(name string, fn DefaultFunc) {
+	if name == "" {
+		panic("parsenv: RegisterDefault with empty name")
+	}
+	if fn == nil {
+		panic(fmt.Sprintf("parsenv: RegisterDefault(%q) with nil DefaultFunc", name))
+	}
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	if _, dup := defaults[name]; dup {
+		panic(fmt.Sprintf("parsenv: RegisterDefault called twice for %q", name))
+	}
+	defaults[name] = fn
+}
+
+// lookupDefault returns the DefaultFunc registered as name.
+func lookupDefault(name string) (DefaultFunc, bool) {
+	defaultsMu.RLock()
+	defer defaultsMu.RUnlock()
+	fn, ok := defaults[name]
+	return fn, ok
+}
+
+// checkDefaultFunc reports a DefaultFunc named by td that isn't registered.
+func checkDefaultFunc(td TagData) error {
+	if td.DefaultFunc == "" {
+		return nil
+	}
+	if _, ok := lookupDefault(td.DefaultFunc); !ok {
+		return fmt.Errorf("unknown default func: %q (register it with RegisterDefault)", td.DefaultFunc)
+	}
+	return nil
+}
+
+// callDefault calls the DefaultFunc named by td, and converts its result to
+// the type t.
+func callDefault(t reflect.Type, td TagData) (any, error) {
+	fn, _ := lookupDefault(td.DefaultFunc)
+	v, err := fn()
+	if err != nil {
+		return nil, fmt.Errorf("default func %s: %w", td.DefaultFunc, err)
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Kind() != t.Kind() || !rv.CanConvert(t) {
+		return nil, fmt.Errorf("default func %s returned %T, which is not a %s", td.DefaultFunc, v, t)
+	}
+	return rv.Convert(t).Interface(), nil
+}
+
+// setDefault sets the field to the result of its DefaultFunc, or, for Lazy
+// fields, defers that to the first call to Get.
+func setDefault(val reflect.Value, spec fieldSpec) error {
+	if lf, ok := asLazy(val); ok {
+		lf.setDefault(spec.tag)
+		return nil
+	}
+	v, err := callDefault(spec.field.Type, spec.tag)
+	if err != nil {
+		return fmt.Errorf("cannot compute default of field %s: %w", spec.path, err)
+	}
+	setField(val, v)
+	return nil
+}
+
+// Lazy holds the value of a field of type T whose default, given with
+// `defaultFunc=<name>`, is only computed when the value is first asked for
+// with Get, and only if the variable was unset. Otherwise, Lazy fields are
+// loaded, described, and formatted like fields of type T.
+//
+// A Lazy must not be copied after it was loaded.
+type Lazy[T any] struct {
+	mu  sync.Mutex
+	val T
+	def func() (any, error) // pending default, if any
+	err error
+}
+
+// Get returns the value, computing the default first if it is still
+// pending. Errors of the DefaultFunc are returned by this and all later
+// calls.
+func (l *Lazy[T]) Get() (T, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.def != nil {
+		v, err := l.def()
+		l.def = nil
+		if err != nil {
+			l.err = err
+		} else {
+			l.val = v.(T)
+		}
+	}
+	return l.val, l.err
+}
+
+func (l *Lazy[T]) lazyType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (l *Lazy[T]) set(v any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.val, l.def, l.err = v.(T), nil, nil
+}
+
+func (l *Lazy[T]) setDefault(td TagData) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.def = func() (any, error) { return callDefault(reflect.TypeFor[T](), td) }
+	l.err = nil
+}
+
+func (l *Lazy[T]) peek() (any, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.val, l.def == nil && l.err == nil
+}
+
+// lazyField is implemented by *Lazy[T] for every T.
+type lazyField interface {
+	lazyType() reflect.Type
+	set(v any)
+	setDefault(td TagData)
+	peek() (v any, ok bool)
+}
+
+var lazyFieldType = reflect.TypeFor[lazyField]()
+
+// lazyElem returns T, if t is a Lazy[T].
+func lazyElem(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Struct || !reflect.PointerTo(t).Implements(lazyFieldType) {
+		return nil, false
+	}
+	return reflect.New(t).Interface().(lazyField).lazyType(), true
+}
+
+// asLazy returns the Lazy v is, if any.
+func asLazy(v reflect.Value) (lazyField, bool) {
+	if _, ok := lazyElem(v.Type()); !ok {
+		return nil, false
+	}
+	if !v.CanAddr() {
+		// copy the value, so that its methods can be called
+		c := reflect.New(v.Type())
+		c.Elem().Set(v)
+		return c.Interface().(lazyField), true
+	}
+	if !v.CanInterface() {
+		v = exportField(v)
+	}
+	return v.Addr().Interface().(lazyField), true
+}
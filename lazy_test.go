@@ -0,0 +1,64 @@
+package parsenv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoadDefaultFunc(t *testing.T) {
+	calls := 0
+	RegisterDefault("test-counter", func() (any, error) {
+		calls++
+		return calls, nil
+	})
+	RegisterDefault("test-failing", func() (any, error) {
+		return nil, errors.New("no entropy")
+	})
+	var myConfig struct {
+		Eager   int       `cfg:"defaultFunc=test-counter"`
+		Lazy    Lazy[int] `cfg:"defaultFunc=test-counter"`
+		Set     Lazy[int] `cfg:"defaultFunc=test-counter"`
+		Failing Lazy[int] `cfg:"defaultFunc=test-failing"`
+	}
+	l := MapLookuper{"SET": "42"}
+	var report Report
+
+	if err := Load(&myConfig, WithLookuper(l), WithReport(&report)); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.Eager != 1 || calls != 1 {
+		t.Errorf("expected the default of Eager to be computed by Load, got: %d (%d calls)", myConfig.Eager, calls)
+	}
+	if v, err := myConfig.Set.Get(); v != 42 || err != nil {
+		t.Errorf("expected the value of SET, got: %d, %v", v, err)
+	}
+	if report.Fields[1].Source != SourceDefault || report.Fields[1].Value != "" || report.Fields[2].Value != "42" {
+		t.Errorf("unexpected report: %+v", report.Fields)
+	}
+	if calls != 1 {
+		t.Errorf("expected the default of Lazy not to be computed before Get, got %d calls", calls)
+	}
+	for range 2 {
+		if v, err := myConfig.Lazy.Get(); v != 2 || err != nil {
+			t.Errorf("expected the default to be computed once, got: %d, %v", v, err)
+		}
+	}
+	if _, err := myConfig.Failing.Get(); err == nil || !strings.Contains(err.Error(), "no entropy") {
+		t.Errorf("expected the error of the default func, got: %v", err)
+	}
+
+	var invalid struct {
+		Unknown  string `cfg:"defaultFunc=test-unknown"`
+		Both     string `cfg:"defaultFunc=test-counter;default=1"`
+		Mismatch string `cfg:"defaultFunc=test-counter"`
+	}
+	err := Load(&invalid, WithLookuper(MapLookuper{}))
+	var lerr *LoadError
+	if !errors.As(err, &lerr) || len(lerr.Errs) != 3 {
+		t.Fatalf("expected three errors, got: %v", err)
+	}
+	if !strings.Contains(lerr.Errs[2].Error(), "which is not a string") {
+		t.Errorf("expected an error about the type of the default, got: %v", lerr.Errs[2])
+	}
+}
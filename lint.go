@@ -0,0 +1,53 @@
+package parsenv
+
+import (
+	"errors"
+	"reflect"
+)
+
+// lintTag reports combinations of properties in td that contradict each
+// other, or of which one never has an effect, so that the struct definition
+// is fixed instead of one behavior being picked silently.
+func lintTag(td TagData) error {
+	var errs []error
+	if td.Ignored && !reflect.DeepEqual(td, TagData{Ignored: true}) {
+		errs = append(errs, errors.New("ignored field (-) must not have other properties"))
+	}
+	if td.Required && td.Default != "" {
+		errs = append(errs, errors.New("required field must not have a default value, which would never be used"))
+	}
+	if td.DefaultFunc != "" && (td.Default != "" || td.Required || td.RequiredIf != "") {
+		errs = append(errs, errors.New("field with defaultFunc must not have a default value or be required"))
+	}
+	if td.Required && len(td.RequiredIn) > 0 {
+		errs = append(errs, errors.New("required field must not be restricted with requiredIn"))
+	}
+	if td.RequiredIf != "" && (td.Required || len(td.RequiredIn) > 0) {
+		errs = append(errs, errors.New("required field must not be restricted with requiredIf"))
+	}
+	if td.RequiredIf != "" && td.Default != "" {
+		errs = append(errs, errors.New("requiredIf field must not have a default value, which would always satisfy it"))
+	}
+	if td.Recommended && (td.Required || len(td.RequiredIn) > 0 || td.RequiredIf != "") {
+		errs = append(errs, errors.New("recommended field must not be required"))
+	}
+	if td.Deprecated && (td.Required || len(td.RequiredIn) > 0 || td.RequiredIf != "" || td.Recommended) {
+		errs = append(errs, errors.New("deprecated field must not be required or recommended"))
+	}
+	if td.Fallback && td.Default == "" {
+		errs = append(errs, errors.New("fallback requires a default value to fall back to"))
+	}
+	if td.Expand && td.NoExpand {
+		errs = append(errs, errors.New("field must not be tagged both expand and noexpand"))
+	}
+	if td.File != "" && td.Unset {
+		errs = append(errs, errors.New("file field must not be tagged unset, as its value is not read from a variable"))
+	}
+	if td.File != "" && len(td.Alias) > 0 {
+		errs = append(errs, errors.New("file field must not have aliases, which would never be looked up"))
+	}
+	if td.JSON && (td.Sep != "" || td.KVSep != "" || td.Layout != "" || td.Encoding != "" || td.HasPrefix || td.Unit != "" || td.Min != "" || td.Max != "" || td.Pattern != "") {
+		errs = append(errs, errors.New("json field must not have sep, kvsep, layout, encoding, prefix, unit, min, max, or pattern, which would never be used"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,29 @@
+package parsenv
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLoadLint(t *testing.T) {
+	var myConfig struct {
+		Host    string `cfg:"required;default=localhost"`
+		Legacy  string `cfg:"-;name=FOO"`
+		Token   string `cfg:"required;requiredIn=prod"`
+		DSN     string `cfg:"recommended;required"`
+		Retries int    `cfg:"fallback"`
+		Ignored string `cfg:"-"`
+		Port    int    `cfg:"fallback;default=80"`
+	}
+	err := Load(&myConfig)
+	var lerr *LoadError
+	if !errors.As(err, &lerr) || len(lerr.Errs) != 5 {
+		t.Fatalf("expected five errors, got: %v", err)
+	}
+	for i, field := range []string{"Host", "Legacy", "Token", "DSN", "Retries"} {
+		var terr *TagError
+		if !errors.As(lerr.Errs[i], &terr) || terr.Field != field {
+			t.Errorf("expected a *TagError for %s, got: %v", field, lerr.Errs[i])
+		}
+	}
+}
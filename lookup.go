@@ -0,0 +1,145 @@
+package parsenv
+
+import (
+	"net/url"
+	"os"
+	"strings"
+)
+
+// A Lookuper retrieves the value of a variable by its name. The boolean
+// reports whether the variable is present at all.
+//
+// Per default, Load looks up variables in the process environment. Setting
+// Options.Lookuper allows values to come from somewhere else, which is
+// useful in environments that don't have a real process environment (e.g.
+// js/wasm in a browser) and in tests.
+type Lookuper interface {
+	Lookup(name string) (string, bool)
+}
+
+// The LookuperFunc type is an adapter to allow the use of ordinary functions
+// as a Lookuper.
+type LookuperFunc func(name string) (string, bool)
+
+// Lookup calls f(name).
+func (f LookuperFunc) Lookup(name string) (string, bool) {
+	return f(name)
+}
+
+// A Source is a Lookuper whose lookups can fail, for example because they
+// involve network requests or external programs. Load reports such failures
+// as a *LookupError, instead of treating the variable as absent.
+type Source interface {
+	Lookuper
+
+	// LookupErr is like Lookup, but also returns an error if the value could
+	// not be retrieved.
+	LookupErr(name string) (string, bool, error)
+}
+
+// A Snapshotter is a Lookuper whose values can change while a struct is
+// loaded, for example because updates are streamed into it in the
+// background. Snapshot returns a Lookuper serving the values as they are at
+// the time of the call, which isn't affected by later updates. Reloader
+// loads each new configuration from a snapshot, so that it never mixes old
+// and new values.
+type Snapshotter interface {
+	Lookuper
+
+	Snapshot() (Lookuper, error)
+}
+
+// snapshot returns a snapshot of l, if it is a Snapshotter, or else l itself.
+func snapshot(l Lookuper) (Lookuper, error) {
+	if s, ok := l.(Snapshotter); ok {
+		return s.Snapshot()
+	}
+	return l, nil
+}
+
+// lookupErr looks up name with l, returning an error if l is a Source.
+func lookupErr(l Lookuper, name string) (string, bool, error) {
+	if s, ok := l.(Source); ok {
+		return s.LookupErr(name)
+	}
+	val, ok := l.Lookup(name)
+	return val, ok, nil
+}
+
+// EnvLookuper looks up variables in the process environment.
+var EnvLookuper Lookuper = LookuperFunc(os.LookupEnv)
+
+// MapLookuper looks up variables in a map.
+type MapLookuper map[string]string
+
+// Lookup returns m[name].
+func (m MapLookuper) Lookup(name string) (string, bool) {
+	val, ok := m[name]
+	return val, ok
+}
+
+// QueryLookuper looks up variables in the query parameters of a URL.
+// If a parameter is given multiple times, the first value is used.
+//
+//	u, _ := url.Parse("https://example.com/?API_URL=https://api.example.com")
+//	parsenv.Load(&cfg, parsenv.WithLookuper(parsenv.QueryLookuper(u.Query())))
+type QueryLookuper url.Values
+
+// Lookup returns the first value of the query parameter name.
+func (q QueryLookuper) Lookup(name string) (string, bool) {
+	vals, ok := q[name]
+	if !ok || len(vals) == 0 {
+		return "", false
+	}
+	return vals[0], true
+}
+
+// ArgsLookuper looks up variables in command line arguments of the form
+// NAME=value. Arguments not matching that form are ignored. If a variable is
+// given multiple times, the last value is used.
+//
+//	// prog DEBUG=true PORT=8080
+//	l := parsenv.ArgsLookuper(os.Args[1:])
+func ArgsLookuper(args []string) Lookuper {
+	m := MapLookuper{}
+	for _, arg := range args {
+		if name, val, ok := strings.Cut(arg, "="); ok && name != "" && !strings.HasPrefix(name, "-") {
+			m[name] = val
+		}
+	}
+	return m
+}
+
+// MultiLookuper returns a Source that asks each of the given lookupers in
+// order, and returns the first value found. Lookups stop at the first error.
+func MultiLookuper(lookupers ...Lookuper) Source {
+	return multiLookuper(lookupers)
+}
+
+type multiLookuper []Lookuper
+
+func (m multiLookuper) Lookup(name string) (string, bool) {
+	val, ok, _ := m.LookupErr(name)
+	return val, ok
+}
+
+func (m multiLookuper) LookupErr(name string) (string, bool, error) {
+	for _, l := range m {
+		if val, ok, err := lookupErr(l, name); err != nil || ok {
+			return val, ok, err
+		}
+	}
+	return "", false, nil
+}
+
+// Snapshot snapshots each of the lookupers that is a Snapshotter.
+func (m multiLookuper) Snapshot() (Lookuper, error) {
+	snap := make(multiLookuper, len(m))
+	for i, l := range m {
+		var err error
+		if snap[i], err = snapshot(l); err != nil {
+			return nil, err
+		}
+	}
+	return snap, nil
+}
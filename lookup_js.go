@@ -0,0 +1,37 @@
+//go:build js && wasm
+
+package parsenv
+
+import "syscall/js"
+
+// JSObjectLookuper looks up variables as string properties of a JavaScript
+// object. Properties that are undefined or null are treated as absent; all
+// other values are converted to strings.
+//
+//	// <script>globalThis.APP_CONFIG = { API_URL: "https://api.example.com" }</script>
+//	l := parsenv.JSObjectLookuper(js.Global().Get("APP_CONFIG"))
+//	err := parsenv.Load(&cfg, parsenv.WithLookuper(l))
+func JSObjectLookuper(obj js.Value) Lookuper {
+	return LookuperFunc(func(name string) (string, bool) {
+		if obj.IsUndefined() || obj.IsNull() {
+			return "", false
+		}
+		val := obj.Get(name)
+		if val.IsUndefined() || val.IsNull() {
+			return "", false
+		}
+		return val.String(), true
+	})
+}
+
+// JSLocationLookuper looks up variables in the query parameters of the
+// page's current location (window.location.search).
+func JSLocationLookuper() Lookuper {
+	return LookuperFunc(func(name string) (string, bool) {
+		params := js.Global().Get("URLSearchParams").New(js.Global().Get("location").Get("search"))
+		if !params.Call("has", name).Bool() {
+			return "", false
+		}
+		return params.Call("get", name).String(), true
+	})
+}
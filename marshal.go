@@ -0,0 +1,176 @@
+package parsenv
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommandEnv appends the fields of cfg, formatted as KEY=value pairs, to a
+// copy of the base environment, for launching child processes with a config
+// struct. Entries of base that are overridden by cfg are removed.
+//
+//	cmd := exec.Command("./worker")
+//	cmd.Env, err = parsenv.CommandEnv(&workerCfg, os.Environ())
+//
+// The variable names are the same Load would use. Nil pointer fields are
+// considered unset and are left out, and so are fields tagged `file`, whose
+// variable holds the path of a file rather than the value, so the child
+// reads the file itself. Entries NAME_FILE of base (see
+// Options.FileVariables) are removed along with NAME. cfg may be a struct or
+// a pointer to a struct, otherwise CommandEnv panics.
+func CommandEnv(cfg any, base []string, opts ...Option) ([]string, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		panic("parsenv.CommandEnv: must pass a structure or a pointer to a structure")
+	}
+	if !v.CanAddr() {
+		// unexported fields can only be read through their address
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		v = c
+	}
+	o := makeOptions(opts)
+	specs, errs := compileStruct(v.Type(), o)
+	vars := map[string]string{}
+	var pairs []string
+	for _, spec := range specs {
+		fv := v.FieldByIndex(spec.index)
+		if fv.Kind() == reflect.Pointer && fv.IsNil() {
+			continue // unset
+		}
+		if spec.tag.File != "" {
+			continue // the variable would be taken as the path of the file
+		}
+		strVal, err := formatValue(fv, spec.tag)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot format field %s: %w", spec.path, err))
+			continue
+		}
+		vars[spec.name] = strVal
+		pairs = append(pairs, spec.name+"="+strVal)
+	}
+	if err := newLoadError(errs, o); err != nil {
+		return nil, err
+	}
+	env := slices.DeleteFunc(slices.Clone(base), func(kv string) bool {
+		key, _, _ := strings.Cut(kv, "=")
+		_, overridden := vars[key]
+		if name, ok := strings.CutSuffix(key, fileSuffix); ok && !overridden {
+			_, overridden = vars[name]
+		}
+		return overridden
+	})
+	return append(env, pairs...), nil
+}
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// formatValue is the inverse of parseValue, formatting val as a string that
+// parseValue would parse into the same value.
+func formatValue(val reflect.Value, td TagData) (string, error) {
+	if !val.CanInterface() && val.CanAddr() {
+		// unexported field, make it (and its elements) accessible
+		val = exportField(val)
+	}
+	if lf, ok := asLazy(val); ok {
+		v, ok := lf.peek()
+		if !ok {
+			return "", nil // the default is still pending
+		}
+		return formatValue(reflect.ValueOf(v), td)
+	}
+	if td.JSON {
+		doc, err := json.Marshal(val.Interface())
+		return string(doc), err
+	}
+	if td.Unit != "" && isUnitKind(val.Kind()) {
+		return formatQuantity(val, td.Unit)
+	}
+	if val.Type() == timeType {
+		return val.Interface().(time.Time).Format(timeLayout(td)), nil
+	}
+	if val.Type() == durationType {
+		return val.Interface().(time.Duration).String(), nil
+	}
+	if val.Type() == urlType {
+		u := val.Interface().(url.URL)
+		return u.String(), nil
+	}
+	if val.Type() == keyValueType {
+		return formatKeyValue(val.Interface().(KeyValue), td), nil
+	}
+	if val.Type() == fileModeType {
+		return formatFileMode(fs.FileMode(val.Uint())), nil
+	}
+	if val.Type() == locationType {
+		loc := reflect.New(locationType)
+		loc.Elem().Set(val)
+		return loc.Interface().(*time.Location).String(), nil
+	}
+	if reflect.PointerTo(val.Type()).Implements(textMarshalerType) {
+		// copy the value, so that methods with a pointer receiver can be
+		// called, even if val is not addressable
+		ptr := reflect.New(val.Type())
+		ptr.Elem().Set(val)
+		text, err := ptr.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	switch val.Kind() {
+	default:
+		return "", fmt.Errorf("unsupported type: %s", val.Type())
+	case reflect.String:
+		return val.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(val.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(val.Float(), 'g', -1, val.Type().Bits()), nil
+	case reflect.Bool:
+		return td.boolWords().format(val.Bool()), nil
+	case reflect.Pointer:
+		if val.IsNil() {
+			return "", nil
+		}
+		return formatValue(val.Elem(), td)
+	case reflect.Slice:
+		if isBytes(val.Type()) {
+			return encodeBytes(val.Bytes(), td)
+		}
+		elems := make([]string, val.Len())
+		for i := range elems {
+			elem, err := formatValue(val.Index(i), td)
+			if err != nil {
+				return "", err
+			}
+			elems[i] = elem
+		}
+		return strings.Join(elems, sliceSep(td)), nil
+	case reflect.Map:
+		pairs := make([]string, 0, val.Len())
+		for iter := val.MapRange(); iter.Next(); {
+			key, err := formatValue(iter.Key(), td)
+			if err != nil {
+				return "", err
+			}
+			elem, err := formatValue(iter.Value(), td)
+			if err != nil {
+				return "", err
+			}
+			pairs = append(pairs, key+mapKVSep(td)+elem)
+		}
+		slices.Sort(pairs)
+		return strings.Join(pairs, sliceSep(td)), nil
+	}
+}
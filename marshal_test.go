@@ -0,0 +1,165 @@
+package parsenv
+
+import (
+	"net/netip"
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCommandEnv(t *testing.T) {
+	cfg := testConfig{
+		Foo: "ignored",
+		Bar: "bar value",
+		Zab: true,
+		Wou: 5,
+		Eew: 6.7,
+	}
+	env, err := CommandEnv(&cfg, []string{"PATH=/usr/bin", "BAR=old", "HOME=/root"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"PATH=/usr/bin",
+		"HOME=/root",
+		"BAR=bar value",
+		"BAZ=",
+		"ZaB=true",
+		"RaB=",
+		"oOF=",
+		"UWA=0",
+		"wou=5",
+		"EEW=6.7",
+	}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %#v, got: %#v", expected, env)
+	}
+
+	var loaded testConfig
+	l := MultiLookuper(MapLookuper{"oOF": "required"}, ArgsLookuper(env))
+	if err := Load(&loaded, WithLookuper(l)); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Bar != cfg.Bar || loaded.Zab != cfg.Zab || loaded.Wou != cfg.Wou || loaded.Eew != cfg.Eew {
+		t.Errorf("expected round trip to preserve values, got: %#v", loaded)
+	}
+}
+
+func TestCommandEnvTime(t *testing.T) {
+	cfg := struct {
+		Day time.Time `cfg:"layout=2006-01-02"`
+	}{Day: time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)}
+	env, err := CommandEnv(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"DAY=2025-06-30"}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %#v, got: %#v", expected, env)
+	}
+}
+
+func TestCommandEnvSlice(t *testing.T) {
+	cfg := struct {
+		Hosts []string
+		Ports []int `cfg:"sep=|"`
+	}{Hosts: []string{"a", "b"}, Ports: []int{80, 443}}
+	env, err := CommandEnv(&cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"HOSTS=a,b", "PORTS=80|443"}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %#v, got: %#v", expected, env)
+	}
+}
+
+func TestCommandEnvMap(t *testing.T) {
+	cfg := struct {
+		Labels map[string]string
+		Limits map[string]int `cfg:"kvsep=:"`
+	}{Labels: map[string]string{"team": "core", "env": "prod"}, Limits: map[string]int{"cpu": 2}}
+	env, err := CommandEnv(&cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"LABELS=env=prod,team=core", "LIMITS=cpu:2"}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %#v, got: %#v", expected, env)
+	}
+}
+
+func TestCommandEnvFloat32(t *testing.T) {
+	cfg := struct{ Ratio float32 }{Ratio: 0.1}
+	env, err := CommandEnv(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"RATIO=0.1"}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %#v, got: %#v", expected, env)
+	}
+}
+
+func TestCommandEnvPointer(t *testing.T) {
+	limit := 0
+	cfg := struct {
+		Limit *int
+		Label *string
+	}{Limit: &limit}
+	env, err := CommandEnv(cfg, []string{"LABEL=inherited"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"LABEL=inherited", "LIMIT=0"}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %#v, got: %#v", expected, env)
+	}
+}
+
+func TestCommandEnvTextMarshaler(t *testing.T) {
+	cfg := struct {
+		Addr    netip.Addr
+		Allowed map[string]netip.Prefix
+	}{Addr: netip.MustParseAddr("10.0.0.1"), Allowed: map[string]netip.Prefix{"lan": netip.MustParsePrefix("10.0.0.0/8")}}
+	env, err := CommandEnv(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"ADDR=10.0.0.1", "ALLOWED=lan=10.0.0.0/8"}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %#v, got: %#v", expected, env)
+	}
+}
+
+type commandEnvConfig struct {
+	Host     string `cfg:"required"`
+	Password string `cfg:"required;secret;file=testdata/db_password"`
+	Token    string `cfg:"secret"`
+}
+
+func TestCommandEnvChild(t *testing.T) {
+	if os.Getenv("PARSENV_TEST_CHILD") == "" {
+		t.Skip("only run as child process of TestCommandEnvRoundTrip")
+	}
+	var cfg commandEnvConfig
+	if err := Load(&cfg, WithFileVariables()); err != nil {
+		t.Fatal(err)
+	}
+	if cfg != (commandEnvConfig{Host: "db.internal", Password: "hunter2", Token: "s3cret"}) {
+		t.Errorf("unexpected config in child: %+v", cfg)
+	}
+}
+
+func TestCommandEnvRoundTrip(t *testing.T) {
+	cfg := commandEnvConfig{Host: "db.internal", Password: "hunter2", Token: "s3cret"}
+	base := []string{"PARSENV_TEST_CHILD=1", "HOST_FILE=/nonexistent", "TOKEN_FILE=/nonexistent"}
+	env, err := CommandEnv(&cfg, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"PARSENV_TEST_CHILD=1", "HOST=db.internal", "TOKEN=s3cret"}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %#v, got: %#v", expected, env)
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCommandEnvChild$", "-test.v")
+	cmd.Env = env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("child failed to load the config: %v\n%s", err, out)
+	}
+}
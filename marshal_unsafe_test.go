@@ -0,0 +1,26 @@
+//go:build !parsenv_nounsafe
+
+package parsenv
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCommandEnvUnexported(t *testing.T) {
+	cfg := struct {
+		host    string
+		expires time.Time `cfg:"layout=2006-01-02"`
+		addr    netip.Addr
+		ports   []int
+	}{host: "db.internal", expires: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), addr: netip.MustParseAddr("10.0.0.1"), ports: []int{80, 443}}
+	env, err := CommandEnv(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"HOST=db.internal", "EXPIRES=2024-03-01", "ADDR=10.0.0.1", "PORTS=80,443"}; !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %#v, got: %#v", expected, env)
+	}
+}
@@ -0,0 +1,50 @@
+package parsenv
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A Decoder parses the value of a variable into a value of the type of the
+// field described by fi (or a type convertible to it).
+type Decoder func(fi FieldInfo, val string) (any, error)
+
+// A Middleware wraps the Decoder that parses the value of each field, to
+// add behavior across all fields without changing how values are parsed,
+// e.g. to normalize values, or to time parsing:
+//
+//	trim := func(next parsenv.Decoder) parsenv.Decoder {
+//		return func(fi parsenv.FieldInfo, val string) (any, error) {
+//			return next(fi, strings.TrimSpace(val))
+//		}
+//	}
+//	err := parsenv.Load(&cfg, parsenv.WithMiddleware(trim))
+//
+// A Middleware may also return a value without calling next at all.
+type Middleware func(next Decoder) Decoder
+
+// parseDecoder is the innermost Decoder, parsing values like Load does
+// without middleware.
+func parseDecoder(fi FieldInfo, val string) (any, error) {
+	return parseValue(fi.Type, val, fi.Tag)
+}
+
+// decodeValue parses val into the type of the field, passing it through
+// Options.Middleware.
+func decodeValue(spec fieldSpec, val string, opts Options) (any, error) {
+	if len(opts.Middleware) == 0 {
+		return parseValue(spec.field.Type, val, spec.tag)
+	}
+	dec := Decoder(parseDecoder)
+	for i := len(opts.Middleware) - 1; i >= 0; i-- {
+		dec = opts.Middleware[i](dec)
+	}
+	v, err := dec(FieldInfo{Path: spec.path, Name: spec.name, Type: spec.field.Type, Tag: spec.tag}, val)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil || !reflect.ValueOf(v).CanConvert(spec.field.Type) {
+		return nil, fmt.Errorf("decoder returned %T for a field of type %s", v, spec.field.Type)
+	}
+	return v, nil
+}
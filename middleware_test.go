@@ -0,0 +1,56 @@
+package parsenv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadMiddleware(t *testing.T) {
+	var myConfig struct {
+		Region  string
+		Port    int `cfg:"default=8080"`
+		Retries int
+	}
+	t.Setenv("REGION", "  EU-West ")
+	t.Setenv("RETRIES", "three")
+
+	var order []string
+	var decoded []string
+	trace := func(next Decoder) Decoder {
+		return func(fi FieldInfo, val string) (any, error) {
+			order = append(order, "trace")
+			decoded = append(decoded, fi.Name)
+			return next(fi, val)
+		}
+	}
+	normalize := func(next Decoder) Decoder {
+		return func(fi FieldInfo, val string) (any, error) {
+			order = append(order, "normalize")
+			if fi.Name == "RETRIES" && val == "three" {
+				return 3, nil
+			}
+			return next(fi, strings.ToLower(strings.TrimSpace(val)))
+		}
+	}
+	if err := Load(&myConfig, WithMiddleware(trace, normalize)); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.Region != "eu-west" || myConfig.Port != 8080 || myConfig.Retries != 3 {
+		t.Errorf("expected the normalized values, got: %+v", myConfig)
+	}
+	if strings.Join(decoded, ",") != "REGION,PORT,RETRIES" {
+		t.Errorf("expected every value to be decoded, got: %v", decoded)
+	}
+	if order[0] != "trace" || order[1] != "normalize" {
+		t.Errorf("expected the first middleware to be outermost, got: %v", order)
+	}
+
+	wrongType := func(next Decoder) Decoder {
+		return func(fi FieldInfo, val string) (any, error) {
+			return []string{val}, nil
+		}
+	}
+	if err := Load(&myConfig, WithMiddleware(wrongType)); err == nil || !strings.Contains(err.Error(), "decoder returned []string") {
+		t.Errorf("expected an error for a value of the wrong type, got: %v", err)
+	}
+}
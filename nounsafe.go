@@ -0,0 +1,15 @@
+//go:build parsenv_nounsafe
+
+package parsenv
+
+import "reflect"
+
+const unsafeAllowed = false
+
+func setUnexportedField(field reflect.Value, value any) {
+	panic("parsenv: unexported fields cannot be set when built with parsenv_nounsafe")
+}
+
+func exportField(field reflect.Value) reflect.Value {
+	panic("parsenv: unexported fields cannot be read when built with parsenv_nounsafe")
+}
@@ -0,0 +1,34 @@
+//go:build parsenv_nounsafe
+
+package parsenv
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLoadNoUnsafeBuild(t *testing.T) {
+	var myConfig struct {
+		Exported   string
+		unexported string
+		ignored    string `cfg:"-"`
+	}
+	t.Setenv("EXPORTED", "exported value")
+	t.Setenv("UNEXPORTED", "unexported value")
+
+	err := Load(&myConfig)
+	var lerr *LoadError
+	if !errors.As(err, &lerr) || len(lerr.Errs) != 1 {
+		t.Fatalf("expected one error, got: %v", err)
+	}
+	var terr *TagError
+	if !errors.As(lerr.Errs[0], &terr) || terr.Field != "unexported" {
+		t.Errorf("expected a *TagError for unexported, got: %v", lerr.Errs[0])
+	}
+	if expected := "invalid cfg tag on field unexported: field is unexported, but loading unexported fields is disabled (tag it with - to skip it)"; err.Error() != expected {
+		t.Errorf("expected %q, got: %q", expected, err.Error())
+	}
+	if myConfig.Exported != "exported value" || myConfig.unexported != "" {
+		t.Errorf("expected only the exported field to be loaded, got: %+v", myConfig)
+	}
+}
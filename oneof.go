@@ -0,0 +1,44 @@
+package parsenv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// parseOneOf parses val like parseValue, but only accepts values equal to
+// one of the values listed in the `oneof` property of td.
+func parseOneOf(t reflect.Type, val string, td TagData) (any, error) {
+	allowed := td.OneOf
+	td.OneOf = nil
+	v, err := parseValue(t, val, td)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range allowed {
+		if av, err := parseValue(t, a, td); err == nil && av == v {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("value %s is not one of %s", val, strings.Join(allowed, ", "))
+}
+
+// checkOneOf reports whether a field of type t can be tagged `oneof` with
+// the values of td, i.e. whether t (or the element type of a pointer or
+// slice) is comparable, and all values can be parsed into it.
+func checkOneOf(t reflect.Type, td TagData) error {
+	for (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice) && !isBytes(t) {
+		t = t.Elem()
+	}
+	if !t.Comparable() || t.Kind() == reflect.Struct && !isSingleValue(t) {
+		return fmt.Errorf("oneof is only valid on fields with comparable values, like strings and numbers, or slices of them")
+	}
+	allowed := td.OneOf
+	td.OneOf = nil
+	for _, a := range allowed {
+		if _, err := parseValue(t, a, td); err != nil {
+			return fmt.Errorf("invalid oneof value %q: %w", a, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package parsenv
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadOneOf(t *testing.T) {
+	var myConfig struct {
+		Environment string      `cfg:"oneof=dev|staging|prod"`
+		Replicas    int         `cfg:"oneof=1|3|5;default=3"`
+		Features    []string    `cfg:"oneof=search | export | beta"`
+		LogLevel    *slog.Level `cfg:"oneof=info|warn|error"`
+	}
+	t.Setenv("ENVIRONMENT", "staging")
+	t.Setenv("FEATURES", "export,search")
+	t.Setenv("LOG_LEVEL", "WARN")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.Environment != "staging" {
+		t.Errorf("expected staging, got: %s", myConfig.Environment)
+	}
+	if myConfig.Replicas != 3 {
+		t.Errorf("expected 3, got: %d", myConfig.Replicas)
+	}
+	if !slices.Equal(myConfig.Features, []string{"export", "search"}) {
+		t.Errorf("expected [export search], got: %v", myConfig.Features)
+	}
+	if myConfig.LogLevel == nil || *myConfig.LogLevel != slog.LevelWarn {
+		t.Errorf("expected WARN, got: %v", myConfig.LogLevel)
+	}
+
+	for name, invalid := range map[string]string{
+		"ENVIRONMENT": "production",
+		"REPLICAS":    "2",
+		"FEATURES":    "search,admin",
+		"LOG_LEVEL":   "debug",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, invalid)
+			err := Load(&myConfig)
+			var perr *ParseError
+			if !errors.As(err, &perr) || perr.Name != name {
+				t.Fatalf("expected a *ParseError for %s, got: %v", name, err)
+			}
+			if !strings.Contains(perr.Error(), "is not one of") {
+				t.Errorf("expected the error to list the allowed values, got: %v", perr)
+			}
+		})
+	}
+}
+
+func TestLoadOneOfTagErrors(t *testing.T) {
+	var myConfig struct {
+		Replicas int               `cfg:"oneof=1|three"`
+		Mode     string            `cfg:"oneof=a|b;default=c"`
+		Labels   map[string]string `cfg:"oneof=a|b"`
+		Empty    string            `cfg:"oneof=a||b"`
+	}
+	err := Load(&myConfig)
+	var lerr *LoadError
+	if !errors.As(err, &lerr) || len(lerr.Errs) != 4 {
+		t.Fatalf("expected four errors, got: %v", err)
+	}
+	for i, field := range []string{"Replicas", "Mode", "Labels", "Empty"} {
+		var terr *TagError
+		if !errors.As(lerr.Errs[i], &terr) || terr.Field != field {
+			t.Errorf("expected a *TagError for %s, got: %v", field, lerr.Errs[i])
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package parsenv
+
+import (
+	"os"
+	"time"
+)
+
+// Options influence how Load reads the environment into a struct.
+// The zero value is ready to use and corresponds to the default behavior.
+type Options struct {
+	// Strict makes Load return an error for fields whose type can never be
+	// loaded from the environment (functions, channels, locks, ...), instead
+	// of silently skipping them.
+	Strict bool
+
+	// NoUnsafe makes Load return a *TagError for unexported fields, instead
+	// of setting them using the unsafe package. This is always the case if
+	// parsenv is built with the parsenv_nounsafe build tag.
+	NoUnsafe bool
+
+	// Lookuper is used to look up the values of variables. If it is nil, the
+	// process environment is used.
+	Lookuper Lookuper
+
+	// Secrets, if set, is used instead of Lookuper to look up the values of
+	// fields tagged `secret`, e.g. a secret manager like OnePassword.
+	Secrets Lookuper
+
+	// MaxErrors limits the number of errors included in the message of the
+	// *LoadError returned by Load. The remaining errors are summarized as
+	// "...and N more", but are still available via LoadError.Errs.
+	// Zero means no limit.
+	MaxErrors int
+
+	// Prompter, if set, is asked for the values of required variables that
+	// are missing, instead of failing right away. This is meant for CLIs
+	// (making the first run more pleasant), not for servers.
+	Prompter Prompter
+
+	// FlagProvider, if set, resolves fields tagged with `flag` from a
+	// feature flag system. Flags unknown to the provider fall back to the
+	// environment.
+	FlagProvider FlagProvider
+
+	// Report, if set, is filled with information about where the value of
+	// each field came from. Fields recorded by a previous call are
+	// discarded.
+	Report *Report
+
+	// Warn, if set, is called with problems that don't make Load fail, like
+	// a *RecommendedWarning for each missing variable tagged `recommended`.
+	Warn func(error)
+
+	// Profile names the environment the program runs in, e.g. "prod". Fields
+	// tagged with `requiredIn=<profiles>` are required if it is one of the
+	// listed profiles.
+	Profile string
+
+	// PrefixEmbedded prefixes the names of fields promoted from embedded
+	// structs with the name of the embedded type, like for nested structs
+	// (LOG_CONFIG_LEVEL instead of LEVEL).
+	PrefixEmbedded bool
+
+	// FallbackToDefaultOnParseError makes Load use the default value of
+	// fields whose value can't be parsed, instead of failing, as if every
+	// field with a default was tagged `fallback`. The *ParseError is passed
+	// to Warn instead.
+	FallbackToDefaultOnParseError bool
+
+	// StrictValues rejects values that are accepted leniently otherwise, as
+	// if every field was tagged `strict`:
+	//   - booleans other than true and false (like yes, on, or 1)
+	//   - integers with a + sign or leading zeros
+	//   - floats that lose precision when stored in a float32
+	//   - whitespace around the elements of slices and maps
+	//   - unknown keys in the objects of fields tagged `json`
+	StrictValues bool
+
+	// ExpandValues expands references to other variables in every value,
+	// as if every field was tagged `expand`, except for fields tagged
+	// `noexpand`.
+	ExpandValues bool
+
+	// FileVariables makes Load read the value of a variable FOO that is
+	// unset from the file named by FOO_FILE, if that is set, following the
+	// convention for secrets mounted by Docker and Kubernetes. A single
+	// trailing newline is removed from the file's contents. It is an error
+	// for both FOO and FOO_FILE to be set.
+	FileVariables bool
+
+	// NameCase controls how field names are converted to the names of
+	// variables.
+	NameCase NameCaseOptions
+
+	// BoolWords, if set, replaces DefaultBoolWords as the words accepted as
+	// booleans.
+	BoolWords BoolWords
+
+	// MaxValueLength, if positive, is the maximum length in bytes of values.
+	// Longer values are rejected with a *ParseError, so that crafted values
+	// can't overwhelm the systems they are passed on to.
+	MaxValueLength int
+
+	// Middleware wraps the parsing of each value, the first one outermost.
+	Middleware []Middleware
+
+	// Now, if set, replaces time.Now as the clock relative defaults like
+	// default=now+1h and the staleness checks of the `rotate` property are
+	// based on, e.g. to pin the time in tests.
+	Now func() time.Time
+
+	simulate bool             // set by Simulate, which must not modify the environment
+	presets  []platformPreset // wrap Lookuper, see PresetHeroku
+}
+
+// An Option modifies the Options used by Load.
+type Option func(*Options)
+
+// WithStrict enables Options.Strict.
+func WithStrict() Option {
+	return func(o *Options) {
+		o.Strict = true
+	}
+}
+
+// WithNoUnsafe enables Options.NoUnsafe.
+func WithNoUnsafe() Option {
+	return func(o *Options) {
+		o.NoUnsafe = true
+	}
+}
+
+// WithLookuper sets Options.Lookuper.
+func WithLookuper(l Lookuper) Option {
+	return func(o *Options) {
+		o.Lookuper = l
+	}
+}
+
+// WithSecrets sets Options.Secrets.
+func WithSecrets(l Lookuper) Option {
+	return func(o *Options) {
+		o.Secrets = l
+	}
+}
+
+// WithMaxErrors sets Options.MaxErrors.
+func WithMaxErrors(n int) Option {
+	return func(o *Options) {
+		o.MaxErrors = n
+	}
+}
+
+// WithPrompt enables prompting for missing required variables on the
+// terminal connected to stdin. If stdin is not a terminal, missing variables
+// are reported as usual.
+func WithPrompt() Option {
+	return WithPrompter(TerminalPrompter{In: os.Stdin, Out: os.Stderr})
+}
+
+// WithPrompter sets Options.Prompter.
+func WithPrompter(p Prompter) Option {
+	return func(o *Options) {
+		o.Prompter = p
+	}
+}
+
+// WithFlagProvider sets Options.FlagProvider.
+func WithFlagProvider(p FlagProvider) Option {
+	return func(o *Options) {
+		o.FlagProvider = p
+	}
+}
+
+// WithReport sets Options.Report.
+func WithReport(r *Report) Option {
+	return func(o *Options) {
+		o.Report = r
+	}
+}
+
+// WithWarn sets Options.Warn.
+//
+//	parsenv.Load(&cfg, parsenv.WithWarn(func(err error) {
+//		slog.Warn("config", "err", err)
+//	}))
+func WithWarn(warn func(error)) Option {
+	return func(o *Options) {
+		o.Warn = warn
+	}
+}
+
+// WithProfile sets Options.Profile.
+//
+//	parsenv.Load(&cfg, parsenv.WithProfile(os.Getenv("APP_ENV")))
+func WithProfile(profile string) Option {
+	return func(o *Options) {
+		o.Profile = profile
+	}
+}
+
+// WithPrefixEmbedded enables Options.PrefixEmbedded.
+func WithPrefixEmbedded() Option {
+	return func(o *Options) {
+		o.PrefixEmbedded = true
+	}
+}
+
+// WithFallbackToDefaultOnParseError enables
+// Options.FallbackToDefaultOnParseError.
+func WithFallbackToDefaultOnParseError() Option {
+	return func(o *Options) {
+		o.FallbackToDefaultOnParseError = true
+	}
+}
+
+// WithStrictValues enables Options.StrictValues.
+func WithStrictValues() Option {
+	return func(o *Options) {
+		o.StrictValues = true
+	}
+}
+
+// WithExpandValues enables Options.ExpandValues.
+func WithExpandValues() Option {
+	return func(o *Options) {
+		o.ExpandValues = true
+	}
+}
+
+// WithFileVariables enables Options.FileVariables.
+func WithFileVariables() Option {
+	return func(o *Options) {
+		o.FileVariables = true
+	}
+}
+
+// WithNameCase sets Options.NameCase.
+func WithNameCase(nc NameCaseOptions) Option {
+	return func(o *Options) {
+		o.NameCase = nc
+	}
+}
+
+// WithBoolWords sets Options.BoolWords.
+func WithBoolWords(bw BoolWords) Option {
+	return func(o *Options) {
+		o.BoolWords = bw
+	}
+}
+
+// WithMaxValueLength sets Options.MaxValueLength.
+func WithMaxValueLength(n int) Option {
+	return func(o *Options) {
+		o.MaxValueLength = n
+	}
+}
+
+// WithMiddleware appends to Options.Middleware.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(o *Options) {
+		o.Middleware = append(o.Middleware, mw...)
+	}
+}
+
+// WithNow sets Options.Now.
+func WithNow(now func() time.Time) Option {
+	return func(o *Options) {
+		o.Now = now
+	}
+}
+
+func makeOptions(opts []Option) (o Options) {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// forField returns the options to look up the value of spec with, which use
+// Options.Secrets as the Lookuper for fields tagged `secret`.
+func (o Options) forField(spec fieldSpec) Options {
+	if spec.tag.Secret && o.Secrets != nil {
+		o.Lookuper = o.Secrets
+	}
+	return o
+}
+
+// lookuper returns the Lookuper wrapped by the presets, in the order they
+// were passed, or nil to use the process environment.
+func (o Options) lookuper() Lookuper {
+	l := o.Lookuper
+	if l == nil && len(o.presets) > 0 {
+		l = EnvLookuper
+	}
+	for _, p := range o.presets {
+		p.base = l
+		l = p
+	}
+	return l
+}
+
+func (o Options) lookup(name string) (string, bool) {
+	l := o.lookuper()
+	if l == nil {
+		return os.LookupEnv(name)
+	}
+	return l.Lookup(name)
+}
+
+func (o Options) lookupErr(name string) (string, bool, error) {
+	l := o.lookuper()
+	if l == nil {
+		val, ok := os.LookupEnv(name)
+		return val, ok, nil
+	}
+	return lookupErr(l, name)
+}
+
+func (o Options) warn(err error) {
+	if o.Warn != nil {
+		o.Warn(err)
+	}
+}
+
+func (o Options) now() time.Time {
+	if o.Now == nil {
+		return time.Now()
+	}
+	return o.Now()
+}
@@ -15,43 +15,222 @@
 // Per default, field names are converted from PascalCase or camelCase to
 // SCREAMING_SNAKE_CASE.
 //
+// Struct-typed fields, including anonymous structs declared inline, are loaded
+// recursively. The names of their fields are prefixed with the name of the
+// parent field, unless a different prefix is set with the `prefix` property:
+//
+//	var myConfig struct {
+//		Database struct {
+//			Host string `cfg:"required"`     // DATABASE_HOST
+//			Port int    `cfg:"default=5432"` // DATABASE_PORT
+//		}
+//		Cache struct {
+//			Host string // REDIS_HOST
+//		} `cfg:"prefix=REDIS_"`
+//	}
+//
+// Fields promoted from embedded structs are loaded as if they were declared
+// in the embedding struct, so config mixins can be shared:
+//
+//	type LogConfig struct {
+//		LogLevel string `cfg:"default=info"` // LOG_LEVEL
+//	}
+//
+//	var myConfig struct {
+//		LogConfig
+//		Port int // PORT
+//	}
+//
+// Fields of structs embedded by pointer are not loaded.
+//
 // For parsing options refer to the documentation of parsenv.TagData.
+//
+// All functions of this package are safe for concurrent use, so the configs of
+// several modules can be loaded in parallel. Loading into the same struct from
+// multiple goroutines at once is a data race, just like any other concurrent
+// write to it.
 package parsenv
 
 import (
+	"encoding"
 	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"net/url"
 	"os"
 	"reflect"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
-	"unsafe"
 )
 
 // The behavior of how the environment is read into a struct can be influenced
 // with the `cfg` struct tag.
 //
 //	var myConfig struct{
-//		foo int     `cfg:"-"`                    // this field is ignored
-//		bar float64 `cfg:"required"`             // return an error if BAR is not found in the environment
-//		baz bool    `cfg:"name=baz"`             // specify a custom name for the env var (per default the field name is converted to SCREAMING_SNAKE_CASE)
-//		zap string  `cfg:"default=hello world"`  // specify a default value
-//		puf int     `cfg:"name=PUFF;default=19"` // use ; to specify multiple properties
+//		foo int               `cfg:"-"`                                   // this field is ignored
+//		bar float64           `cfg:"required"`                            // return an error if BAR is not found in the environment
+//		baz bool              `cfg:"name=baz"`                            // specify a custom name for the env var (per default the field name is converted to SCREAMING_SNAKE_CASE)
+//		usr string            `cfg:"notempty;default=app"`                // reject a variable that is set, but empty, instead of using the default
+//		dsn string            `cfg:"alias=DB_URL,PG_URL"`                 // if the env var is unset or empty, look up the aliases in order (e.g. the old names of renamed variables)
+//		zap string            `cfg:"default=hello world"`                 // specify a default value
+//		puf int               `cfg:"name=PUFF;default=19"`                // use ; to specify multiple properties
+//		dir string            `cfg:"expand;default=$HOME"`                // expand references to other env vars in the value
+//		raw string            `cfg:"noexpand"`                            // take the value literally, even if Options.ExpandValues is set
+//		pwd string            `cfg:"file=/run/secrets/db_password"`       // read the value from the file, or the file at the path the env var is set to
+//		prt int               `cfg:"min=1;max=65535"`                     // reject values out of range (inclusive) for numeric fields, including durations and units like bytes
+//		bkt string            `cfg:"pattern=^[a-z0-9-]+$"`                // reject string values that don't match the regular expression (use ^ and $ to match the whole value, ; can't be used)
+//		hdr string            `cfg:"charset=[:alnum:]._-"`                // reject values with other characters (single characters, ranges like a-z, and classes like [:print:])
+//		bin string            `cfg:"transform=trim,lower"`                // rewrite the value before parsing it, with transforms registered with RegisterTransform
+//		api string            `cfg:"validate=port"`                       // check the value with validators registered with RegisterValidator
+//		tok string            `cfg:"rotate=30d"`                          // warn (see Options.Warn) if the value was changed longer ago than the period (see ModTimeLookuper)
+//		sec string            `cfg:"required;secret"`                     // the value is sensitive (e.g. input is hidden when prompted for)
+//		sid string            `cfg:"secret;unset"`                        // remove the variable from the process environment after loading, so child processes don't inherit it
+//		day time.Time         `cfg:"layout=2006-01-02"`                   // parse time.Time fields with a custom layout (the default is time.RFC3339)
+//		exp time.Time         `cfg:"default=now+24h"`                     // default time.Time fields relative to the time of loading (see Options.Now), like now, now-1h, or now+7d
+//		tmp string            `cfg:"defaultFunc=tempdir"`                 // compute the default with a function registered with RegisterDefault, only if the variable is unset (for Lazy fields, only when Get is first called)
+//		ips []string          `cfg:"sep=|"`                               // split slices on a custom separator (the default is ,)
+//		tag map[string]string `cfg:"kvsep=:"`                             // maps are read from pairs like a=b,c=d, with a custom key/value separator (the default is =)
+//		mws []KeyValue        `cfg:"kvsep=:"`                             // like a map, but keeps the order of the pairs (see KeyValue)
+//		new bool              `cfg:"flag=new-checkout"`                   // resolve from Options.FlagProvider first, falling back to the environment
+//		lsn int               `cfg:"usage=port to listen on"`             // a description of the variable for help texts (see Usage)
+//		url string            `cfg:"example=https://example.com"`         // an example value for documentation and templates (see FieldInfo.Example)
+//		sen string            `cfg:"recommended=errors are not reported"` // optional, but warned about (see Options.Warn) when missing
+//		old string            `cfg:"deprecated=use NEW_NAME"`             // still loaded, but warned about (see Options.Warn) when set
+//		key string            `cfg:"requiredIn=prod,staging"`             // required only if Options.Profile is one of the listed profiles
+//		crt string            `cfg:"requiredIf=TLS=true"`                 // required only if the variable (or the field loaded from it) has the value, or, with requiredIf=<name>, is set
+//		sql DBConfig          `cfg:"prefix=PG_"`                          // use a custom prefix for the fields of a nested struct (PG_HOST instead of SQL_HOST), or none with prefix=
+//		ttl int               `cfg:"fallback;default=60"`                 // use the default if the value can't be parsed, passing the error to Options.Warn
+//		aes []byte            `cfg:"encoding=base64"`                     // decode []byte fields from base64, base64url, or hex (the default is raw, i.e. the bytes of the value)
+//		num float32           `cfg:"strict"`                              // reject values that are only accepted leniently (see Options.StrictValues)
+//		web url.URL           `cfg:"schemes=http,https"`                  // only accept URLs with one of the listed schemes
+//		max int64             `cfg:"unit=bytes"`                          // parse numbers with a unit suffix like 512MiB, with the units registered with RegisterUnits (bytes is built in)
+//		env string            `cfg:"oneof=dev|staging|prod"`              // only accept one of the listed values (for slices, as each element)
+//		col string            `cfg:"widget=color;group=Theme;order=2"`    // hints for UIs editing the config, passed through by Describe as is
+//		pay string            `cfg:"owner=team payments"`                 // who to ask about the variable, included in errors and the schema
+//		lbs map[string]int    `cfg:"weighted"`                            // read weights like a:3,b:1, which must be positive (see WeightedItem for a variant that keeps the order)
+//		ups []Upstream        `cfg:"json"`                                // unmarshal the value as a JSON document with encoding/json (structs are not recursed into)
 //	}
+//
+// Only the first = of a property separates the key from the value, so values
+// may contain = themselves (`cfg:"default=a=b"`).
+//
+// Combinations of properties that contradict each other, or of which one
+// would never have an effect, like `cfg:"required;default=x"`, are invalid.
 type TagData struct {
-	Name     string // name=<name>
-	Default  string // default=<value>
-	Required bool   // required
-	Ignored  bool   // -
+	Name              string        // name=<name>
+	Alias             []string      // alias=<name>,<name>...
+	Default           string        // default=<value>
+	DefaultFunc       string        // defaultFunc=<name>
+	Required          bool          // required
+	NotEmpty          bool          // notempty
+	Unset             bool          // unset
+	Ignored           bool          // -
+	Expand            bool          // expand
+	NoExpand          bool          // noexpand
+	Secret            bool          // secret
+	Layout            string        // layout=<layout>
+	Sep               string        // sep=<separator>
+	KVSep             string        // kvsep=<separator>
+	Flag              bool          // flag, or flag=<key>
+	FlagKey           string        // flag=<key>
+	Example           string        // example=<value>
+	Usage             string        // usage=<description>
+	Recommended       bool          // recommended, or recommended=<reason>
+	RecommendedReason string        // recommended=<reason>
+	Deprecated        bool          // deprecated, or deprecated=<reason>
+	DeprecatedReason  string        // deprecated=<reason>
+	RequiredIn        []string      // requiredIn=<profile>,<profile>...
+	RequiredIf        string        // requiredIf=<name>, or requiredIf=<name>=<value>
+	RequiredIfValue   string        // requiredIf=<name>=<value>
+	Prefix            string        // prefix=<prefix>
+	HasPrefix         bool          // whether prefix=<prefix> is set, possibly to the empty string
+	Fallback          bool          // fallback
+	Encoding          string        // encoding=<raw|base64|base64url|hex>
+	Strict            bool          // strict
+	Schemes           []string      // schemes=<scheme>,<scheme>...
+	JSON              bool          // json
+	Unit              string        // unit=<name>
+	Weighted          bool          // weighted
+	OneOf             []string      // oneof=<value>|<value>...
+	Widget            string        // widget=<widget>
+	Group             string        // group=<group>
+	Order             int           // order=<n>
+	Owner             string        // owner=<owner>
+	File              string        // file=<path>
+	Rotate            time.Duration // rotate=<period>
+	Min               string        // min=<value>
+	Max               string        // max=<value>
+	Pattern           string        // pattern=<regexp>
+	Charset           string        // charset=<characters>
+	Validate          []string      // validate=<name>,<name>...
+	Transform         []string      // transform=<name>,<name>...
+
+	bools   *BoolWords     // Options.BoolWords, if set
+	pattern *regexp.Regexp // compiled Pattern
+	charset *charset       // parsed Charset
 }
 
 // Load reads environment variables into a struct.
 // If the cfg variable passed is not a pointer to a struct, Load will panic.
-// If any of the fields contain invalid `cfg` struct tags, Load will panic also.
+// If any of the fields contain invalid `cfg` struct tags, Load returns a
+// *TagError for each of them.
+// Values that can't be parsed into their field are reported as *ParseError.
 // If one or more fields marked as 'required' don't have a corresponding
-// environment variable, Load will return an error.
-func Load(cfg any) (err error) {
+// environment variable, Load returns a *MissingError for each of them.
+// All errors are collected into a *LoadError, so that a single call to Load
+// reports every problem at once.
+// Fields whose type can't be loaded from the environment, such as functions,
+// channels, interfaces, or locks from the sync package, are skipped, unless
+// Options.Strict is set, in which case they are reported as errors.
+//
+// Booleans are true, t, yes, y, on, or 1, and false, f, no, n, off, or 0,
+// ignoring case. Options.BoolWords replaces these words, e.g. with
+// StrconvBoolWords to only accept what strconv.ParseBool does.
+//
+// Fields of types implementing encoding.TextUnmarshaler are loaded with
+// UnmarshalText. This includes netip.Addr, netip.AddrPort, and
+// netip.Prefix, so bind addresses and CIDR allowlists are validated by Load,
+// as well as regexp.Regexp, so patterns are compiled by Load, and big.Int.
+// big.Float values are parsed with enough precision for all of their digits.
+// slog.Level and slog.LevelVar fields accept the names of levels
+// case-insensitively (debug, info, warn, error), optionally with an offset
+// like DEBUG-4, so LOG_LEVEL can be passed to the logger directly.
+// time.Duration fields are parsed with time.ParseDuration, like TIMEOUT=30s.
+// fs.FileMode (os.FileMode) fields are parsed as octal permission bits, like
+// UMASK=0027, not as decimal integers.
+// *time.Location fields are loaded with time.LoadLocation, from names like
+// Europe/Zurich.
+// TimeWindow fields hold daily windows like "22:00-06:00 Europe/Zurich", Rate
+// fields rate limits like "100/s", and ByteSize fields sizes like "512MiB".
+//
+// Fields tagged `json` are unmarshaled from a JSON document with
+// encoding/json instead, which is how many platforms pass structured config
+// in a single variable. If the field is also `strict`, keys of objects that
+// don't match a field are rejected.
+//
+// Values of the form enc:<name>:<ciphertext> are decrypted before they are
+// parsed, see RegisterDecryptor.
+//
+// After all fields were loaded, the Validate() error method of the struct,
+// and of the structs nested in it, is called, if they have one, for checks
+// across fields (e.g. that a TLS certificate and key are either both set or
+// both unset). Errors are returned as a *ValidationError, along with the
+// errors of the fields.
+//
+// Pointer fields are allocated only if a value (or default value) is found,
+// so that an unset variable can be told apart from one set to the zero value.
+//
+// Unexported fields are set using the unsafe package. If that is not
+// acceptable, either set Options.NoUnsafe, or build with the parsenv_nounsafe
+// build tag to exclude the use of unsafe entirely. In both cases Load returns
+// an error for every unexported field that isn't ignored with `cfg:"-"`.
+func Load(cfg any, opts ...Option) error {
 	cfgRefl := reflect.ValueOf(cfg)
 	cfgType := cfgRefl.Type()
 	if cfgType.Kind() != reflect.Pointer {
@@ -60,70 +239,465 @@ func Load(cfg any) (err error) {
 	if cfgRefl.Elem().Type().Kind() != reflect.Struct {
 		panic("parsenv.Load: must pass a pointer to a structure")
 	}
-	for _, field := range reflect.VisibleFields(cfgType.Elem()) {
-		optionName := changeNameCase(field.Name)
-		td := parseTag(field.Tag.Get("cfg"))
-		if td.Name != "" {
-			optionName = td.Name
+	o := makeOptions(opts)
+	return newLoadError(loadStruct(cfgRefl.Elem(), o), o)
+}
+
+// LoadValue is like Load, but takes a reflect.Value, which is useful for
+// callers that create config types dynamically, e.g. with reflect.New.
+// The value must either be a non-nil pointer to a struct, or an addressable
+// struct, otherwise LoadValue panics.
+//
+//	v := reflect.New(cfgType)
+//	if err := parsenv.LoadValue(v); err != nil {
+//		return err
+//	}
+//	return v.Interface(), nil
+func LoadValue(v reflect.Value, opts ...Option) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			panic("parsenv.LoadValue: must pass a non-nil pointer to a structure")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || !v.CanAddr() {
+		panic("parsenv.LoadValue: must pass a pointer to a structure or an addressable structure")
+	}
+	o := makeOptions(opts)
+	return newLoadError(loadStruct(v, o), o)
+}
+
+// LoadMap collects all environment variables whose names start with prefix
+// into a map. The prefix is stripped from the keys of the map.
+//
+//	// APP_FEATURE_DARK_MODE=on APP_FEATURE_BETA=off
+//	features, err := parsenv.LoadMap("APP_FEATURE_")
+//	// map[BETA:off DARK_MODE:on]
+//
+// Variables with an empty value, as well as a variable named exactly like the
+// prefix, are not included.
+func LoadMap(prefix string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, kv := range os.Environ() {
+		key, val, ok := strings.Cut(kv, "=")
+		if !ok {
+			return m, fmt.Errorf("malformed environment entry: %s", kv)
+		}
+		if name, found := strings.CutPrefix(key, prefix); found && name != "" && val != "" {
+			m[name] = val
 		}
-		if val := cfgRefl.Elem().Field(field.Index[0]); val.IsValid() {
-			if td.Ignored {
-				// ignore
-			} else if strVal := os.Getenv(optionName); strVal != "" {
-				optVal, perr := parseValue(field.Type.Kind(), strVal)
-				err = errors.Join(err, perr)
-				setUnexportedField(val, optVal)
-			} else if td.Default != "" {
-				optVal, perr := parseValue(field.Type.Kind(), td.Default)
-				err = errors.Join(err, perr)
-				setUnexportedField(val, optVal)
-			} else if td.Required {
-				err = errors.Join(err, fmt.Errorf("missing env value for required field: %s", field.Name))
+	}
+	return m, nil
+}
+
+// loadStruct populates the fields of the struct cfgVal.
+func loadStruct(cfgVal reflect.Value, opts Options) []error {
+	specs, errs := compileStruct(cfgVal.Type(), opts)
+	if opts.Report != nil {
+		opts.Report.Fields = nil
+		opts.Report.Time = opts.now()
+	}
+	var conditional []fieldSpec
+	loaded := map[string]bool{}
+	for _, spec := range specs {
+		val := cfgVal.FieldByIndex(spec.index)
+		source := SourceUnset
+		strVal, found, fromEnv, lerr := lookupField(spec, opts)
+		if lerr != nil {
+			errs = append(errs, &LookupError{Field: spec.path, Name: spec.name, Owner: spec.tag.Owner, Err: lerr})
+		} else if found && strVal == "" && spec.tag.NotEmpty {
+			errs = append(errs, &ParseError{Field: spec.path, Name: spec.name, Owner: spec.tag.Owner, Err: errEmptyValue})
+		} else if strVal != "" {
+			source = SourceEnv
+			if spec.tag.Deprecated {
+				opts.warn(&DeprecatedWarning{Field: spec.path, Name: spec.name, Reason: spec.tag.DeprecatedReason, Owner: spec.tag.Owner})
+			}
+			if spec.tag.Rotate > 0 {
+				checkRotation(spec, opts)
+			}
+			if err := setValue(val, spec, strVal, opts); err != nil {
+				if spec.tag.Default != "" && (spec.tag.Fallback || opts.FallbackToDefaultOnParseError) {
+					opts.warn(err)
+					source = SourceDefault
+					err = setValue(val, spec, defaultValue(spec, opts), opts)
+				}
+				if err != nil {
+					errs = append(errs, err)
+				}
+			}
+		} else if spec.tag.Default != "" {
+			source = SourceDefault
+			if err := setValue(val, spec, defaultValue(spec, opts), opts); err != nil {
+				errs = append(errs, err)
 			}
+		} else if spec.tag.DefaultFunc != "" {
+			source = SourceDefault
+			if err := setDefault(val, spec); err != nil {
+				errs = append(errs, err)
+			}
+		} else if spec.tag.Required {
+			if strVal, ok := prompt(spec.name, spec.tag, opts); ok {
+				source = SourcePrompt
+				if err := setValue(val, spec, strVal, opts); err != nil {
+					errs = append(errs, err)
+				}
+			} else {
+				errs = append(errs, &MissingError{Field: spec.path, Name: spec.name, Owner: spec.tag.Owner})
+			}
+		} else if spec.tag.RequiredIf != "" {
+			conditional = append(conditional, spec)
+		} else if spec.tag.Recommended {
+			opts.warn(&RecommendedWarning{Field: spec.path, Name: spec.name, Reason: spec.tag.RecommendedReason, Owner: spec.tag.Owner})
+		}
+		if found && fromEnv && spec.tag.Unset && !opts.simulate {
+			if err := unsetVariables(spec); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if source != SourceUnset {
+			loaded[spec.name] = true
 		}
+		opts.Report.record(spec, val, source)
 	}
-	return err
+	// conditions may refer to fields that come later
+	for _, spec := range conditional {
+		required, err := conditionHolds(cfgVal, specs, loaded, spec.tag, opts)
+		if err != nil {
+			errs = append(errs, &LookupError{Field: spec.path, Name: spec.tag.RequiredIf, Owner: spec.tag.Owner, Err: err})
+		} else if required {
+			errs = append(errs, &MissingError{Field: spec.path, Name: spec.name, Owner: spec.tag.Owner, Condition: requiredIfCondition(spec.tag)})
+		}
+	}
+	return append(errs, validateStruct(cfgVal, "", true, opts)...)
+}
+
+// errEmptyValue is reported for fields tagged `notempty` whose variable is
+// set to the empty string.
+var errEmptyValue = errors.New("variable is set, but empty")
+
+// setValue parses strVal into the type of the field and stores the result in
+// val.
+func setValue(val reflect.Value, spec fieldSpec, strVal string, opts Options) error {
+	if spec.tag.Expand && !strings.HasPrefix(strVal, encPrefix) {
+		strVal = expand(strVal, opts.lookup)
+	}
+	optVal, err := parseField(spec, strVal, opts)
+	if err != nil {
+		// strVal is reported, so that the plaintext of encrypted values
+		// isn't revealed
+		perr := &ParseError{Field: spec.path, Name: spec.name, Value: strVal, Owner: spec.tag.Owner, Err: err}
+		if spec.tag.Secret {
+			perr.Value = redacted
+		}
+		return perr
+	}
+	setField(val, optVal)
+	return nil
+}
+
+// parseField decrypts and transforms strVal, checks it against
+// Options.MaxValueLength and the `charset` of the field, parses it, and
+// runs the validators of the field. For secret fields, the returned error
+// only names the type of the value, see secretError.
+func parseField(spec fieldSpec, strVal string, opts Options) (any, error) {
+	fail := func(err error) (any, error) {
+		if spec.tag.Secret {
+			err = secretError(spec.field.Type, spec.tag)
+		}
+		return nil, err
+	}
+	// errors of decryptValue only quote the ciphertext, which is no secret
+	strVal, err := decryptValue(strVal)
+	if err != nil {
+		return nil, err
+	}
+	if strVal, err = applyTransforms(strVal, spec.tag); err != nil {
+		return fail(err)
+	}
+	if err := checkLength(strVal, opts); err != nil {
+		return fail(err)
+	}
+	if spec.tag.charset != nil {
+		if err := spec.tag.charset.check(strVal, spec.tag); err != nil {
+			return fail(err)
+		}
+	}
+	optVal, err := decodeValue(spec, strVal, opts)
+	if err == nil {
+		err = runValidators(spec.field.Type, optVal, spec.tag)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	return optVal, nil
+}
+
+// isUnloadable reports whether values of type t can never be represented by an
+// environment variable.
+func isUnloadable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Func, reflect.Chan, reflect.Interface, reflect.UnsafePointer:
+		return true
+	case reflect.Pointer, reflect.Slice:
+		return isUnloadable(t.Elem())
+	case reflect.Map:
+		return isUnloadable(t.Key()) || isUnloadable(t.Elem())
+	}
+	switch t.PkgPath() {
+	case "sync", "sync/atomic":
+		return true
+	}
+	return false
 }
 
-func parseTag(rawTag string) (td TagData) {
+func parseTag(rawTag string) (td TagData, err error) {
 	if rawTag == "" {
-		return td
+		return td, nil
 	}
 	rawParts := strings.Split(rawTag, ";")
 	for _, rawProperty := range rawParts {
-		propertyParts := strings.Split(rawProperty, "=")
-		switch len(propertyParts) {
-		default:
-			panic(fmt.Sprintf("invalid format for property in cfg tag: %s", rawProperty)) // @todo: better error message (location?)
-		case 1:
-			switch propertyParts[0] {
+		key, val, hasVal := strings.Cut(rawProperty, "=")
+		if !hasVal {
+			switch key {
 			default:
 			case "-":
 				td.Ignored = true
 			case "required":
 				td.Required = true
+			case "notempty":
+				td.NotEmpty = true
+			case "unset":
+				td.Unset = true
+			case "expand":
+				td.Expand = true
+			case "noexpand":
+				td.NoExpand = true
+			case "secret":
+				td.Secret = true
+			case "flag":
+				td.Flag = true
+			case "recommended":
+				td.Recommended = true
+			case "deprecated":
+				td.Deprecated = true
+			case "fallback":
+				td.Fallback = true
+			case "strict":
+				td.Strict = true
+			case "json":
+				td.JSON = true
+			case "weighted":
+				td.Weighted = true
 			}
-		case 2:
-			key := propertyParts[0]
-			val := propertyParts[1]
-			switch key {
-			default:
-				panic(fmt.Sprintf("unknown property in cfg tag: %s", key))
-			case "name":
-				td.Name = val
-			case "default":
-				td.Default = val
+			continue
+		}
+		switch key {
+		default:
+			return td, fmt.Errorf("unknown property in cfg tag: %q", key)
+		case "name":
+			td.Name = val
+		case "default":
+			td.Default = val
+		case "defaultFunc":
+			td.DefaultFunc = val
+		case "layout":
+			td.Layout = val
+		case "sep":
+			if val == "" {
+				return td, fmt.Errorf("empty separator")
+			}
+			td.Sep = val
+		case "kvsep":
+			if val == "" {
+				return td, fmt.Errorf("empty key/value separator")
+			}
+			td.KVSep = val
+		case "flag":
+			td.Flag = true
+			td.FlagKey = val
+		case "usage":
+			td.Usage = val
+		case "example":
+			td.Example = val
+		case "recommended":
+			td.Recommended = true
+			td.RecommendedReason = val
+		case "deprecated":
+			td.Deprecated = true
+			td.DeprecatedReason = val
+		case "encoding":
+			if !validEncoding(val) {
+				return td, fmt.Errorf("unknown encoding: %q (must be raw, base64, base64url, or hex)", val)
+			}
+			td.Encoding = val
+		case "prefix":
+			td.Prefix = val
+			td.HasPrefix = true
+		case "schemes":
+			for _, scheme := range strings.Split(val, ",") {
+				td.Schemes = append(td.Schemes, strings.TrimSpace(scheme))
+			}
+		case "alias":
+			for _, name := range strings.Split(val, ",") {
+				if name = strings.TrimSpace(name); name == "" {
+					return td, fmt.Errorf("empty name in alias")
+				}
+				td.Alias = append(td.Alias, name)
+			}
+		case "transform":
+			for _, name := range strings.Split(val, ",") {
+				td.Transform = append(td.Transform, strings.TrimSpace(name))
+			}
+		case "validate":
+			for _, name := range strings.Split(val, ",") {
+				td.Validate = append(td.Validate, strings.TrimSpace(name))
 			}
+		case "unit":
+			td.Unit = val
+		case "widget":
+			td.Widget = val
+		case "owner":
+			td.Owner = val
+		case "pattern":
+			td.Pattern = val
+		case "charset":
+			cs, err := parseCharset(val)
+			if err != nil {
+				return td, err
+			}
+			td.Charset = val
+			td.charset = cs
+		case "min":
+			td.Min = val
+		case "max":
+			td.Max = val
+		case "rotate":
+			period, err := parsePeriod(val)
+			if err != nil {
+				return td, err
+			}
+			td.Rotate = period
+		case "file":
+			if val == "" {
+				return td, fmt.Errorf("empty file path")
+			}
+			td.File = val
+		case "group":
+			td.Group = val
+		case "order":
+			order, err := strconv.Atoi(val)
+			if err != nil {
+				return td, fmt.Errorf("order must be an integer: %q", val)
+			}
+			td.Order = order
+		case "oneof":
+			for _, allowed := range strings.Split(val, "|") {
+				if allowed = strings.TrimSpace(allowed); allowed == "" {
+					return td, fmt.Errorf("empty value in oneof")
+				}
+				td.OneOf = append(td.OneOf, allowed)
+			}
+		case "requiredIn":
+			for _, profile := range strings.Split(val, ",") {
+				td.RequiredIn = append(td.RequiredIn, strings.TrimSpace(profile))
+			}
+		case "requiredIf":
+			name, want, _ := strings.Cut(val, "=")
+			if name = strings.TrimSpace(name); name == "" {
+				return td, fmt.Errorf("empty variable name in requiredIf")
+			}
+			td.RequiredIf = name
+			td.RequiredIfValue = want
 		}
 	}
-	return td
+	return td, nil
+}
+
+// EnvName returns the name of the environment variable Load reads for the
+// given struct field. That is either the name specified with the `name`
+// property of the `cfg` tag, or the field's name converted to
+// SCREAMING_SNAKE_CASE, according to opts.NameCase.
+//
+// EnvName does not know about the struct a field is nested in, so the prefix
+// Load adds to fields of nested structs is not included.
+// If the field's `cfg` tag is invalid, the converted field name is returned.
+func EnvName(structField reflect.StructField, opts Options) string {
+	if td, err := parseTag(structField.Tag.Get("cfg")); err == nil && td.Name != "" {
+		return td.Name
+	}
+	return opts.NameCase.NameFor(structField.Name)
+}
+
+// NameFor converts a field name from PascalCase or camelCase to the
+// SCREAMING_SNAKE_CASE name Load uses for the environment variable, with
+// the default NameCaseOptions.
+//
+//	parsenv.NameFor("FrobCount") // FROB_COUNT
+func NameFor(fieldName string) string {
+	return changeNameCase(fieldName)
+}
+
+// NameCaseOptions control where field names are split into the words of the
+// SCREAMING_SNAKE_CASE variable name. Per default, that is only where a
+// lowercase letter is followed by an uppercase one.
+type NameCaseOptions struct {
+	// Digits controls whether digits start or end words.
+	Digits DigitWords
+
+	// SplitAcronyms starts a new word at the last letter of a run of
+	// uppercase letters, if it is followed by a lowercase letter:
+	// HTTPServer becomes HTTP_SERVER instead of HTTPSERVER.
+	SplitAcronyms bool
+}
+
+// DigitWords tells how digits affect the words of a variable name.
+type DigitWords int
+
+const (
+	DigitsJoin      DigitWords = iota // digits don't affect words: S3Bucket becomes S3BUCKET, Ipv6Addr becomes IPV6ADDR
+	DigitsEndWord                     // digits end a word: S3Bucket becomes S3_BUCKET, Ipv6Addr becomes IPV6_ADDR
+	DigitsStartWord                   // digits are words of their own: S3Bucket becomes S_3_BUCKET, Ipv6Addr becomes IPV_6_ADDR
+)
+
+// NameFor is like the NameFor function, but uses nc.
+//
+//	nc := parsenv.NameCaseOptions{Digits: parsenv.DigitsEndWord, SplitAcronyms: true}
+//	nc.NameFor("S3BucketURL")  // S3_BUCKET_URL
+//	nc.NameFor("HTTPServer")   // HTTP_SERVER
+func (nc NameCaseOptions) NameFor(fieldName string) string {
+	return convertNameCase(fieldName, nc)
+}
+
+// startsWord reports whether runes[i] starts a new word.
+func (nc NameCaseOptions) startsWord(runes []rune, i int) bool {
+	prev, cur := runes[i-1], runes[i]
+	if unicode.IsLower(prev) && unicode.IsUpper(cur) {
+		return true
+	}
+	if nc.SplitAcronyms && unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+		return true
+	}
+	switch nc.Digits {
+	case DigitsEndWord:
+		return unicode.IsDigit(prev) && unicode.IsUpper(cur)
+	case DigitsStartWord:
+		return unicode.IsDigit(prev) != unicode.IsDigit(cur) && (unicode.IsLetter(prev) || unicode.IsLetter(cur))
+	}
+	return false
 }
 
 func changeNameCase(name string) string {
+	return convertNameCase(name, NameCaseOptions{})
+}
+
+func convertNameCase(name string, nc NameCaseOptions) string {
 	runes := []rune(name)
+	if len(runes) == 0 {
+		return ""
+	}
 	caseChangeIdxs := []int{0}
 	for i := range runes[1:] {
-		if unicode.IsLower(runes[i]) && unicode.IsUpper(runes[i+1]) {
+		if nc.startsWord(runes, i+1) {
 			caseChangeIdxs = append(caseChangeIdxs, i+1)
 		}
 	}
@@ -142,32 +716,279 @@ func changeNameCase(name string) string {
 	return screamingSnakeCase.String()
 }
 
-func parseValue(kind reflect.Kind, val string) (any, error) {
-	switch kind {
+// timeType is loaded from a single value, even though it is a struct.
+var timeType = reflect.TypeFor[time.Time]()
+
+// durationType is parsed with time.ParseDuration, instead of as an integer.
+var durationType = reflect.TypeFor[time.Duration]()
+
+// urlType is loaded from a single value, even though it is a struct.
+var urlType = reflect.TypeFor[url.URL]()
+
+var bigFloatType = reflect.TypeFor[big.Float]()
+
+var (
+	slogLevelType    = reflect.TypeFor[slog.Level]()
+	slogLevelVarType = reflect.TypeFor[slog.LevelVar]()
+)
+
+// locationType is loaded with time.LoadLocation, usually into a
+// *time.Location field.
+var locationType = reflect.TypeFor[time.Location]()
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// isTextUnmarshaler reports whether values of type t can be loaded with
+// UnmarshalText.
+func isTextUnmarshaler(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// isSingleValue reports whether t is loaded from a single value, even though
+// it is a struct, like time.Time, url.URL, or net/netip.Addr.
+func isSingleValue(t reflect.Type) bool {
+	return t == timeType || t == urlType || t == locationType || t == keyValueType || isTextUnmarshaler(t)
+}
+
+func parseValue(t reflect.Type, val string, td TagData) (any, error) {
+	if (td.Min != "" || td.Max != "") && isUnitKind(t.Kind()) {
+		return parseInRange(t, val, td)
+	}
+	if len(td.OneOf) > 0 && t.Kind() != reflect.Pointer && t.Kind() != reflect.Slice {
+		return parseOneOf(t, val, td)
+	}
+	if td.JSON {
+		return decodeJSON(t, val, td.Strict)
+	}
+	if td.Unit != "" && isUnitKind(t.Kind()) {
+		return parseQuantity(t, val, td.Unit)
+	}
+	if t == fileModeType {
+		return parseFileMode(val)
+	}
+	if t == keyValueType {
+		return parseKeyValue(val, td), nil
+	}
+	if t == durationType {
+		return time.ParseDuration(val)
+	}
+	if td.Strict {
+		if err := checkStrict(t, val); err != nil {
+			return nil, err
+		}
+	}
+	if t == timeType {
+		return time.Parse(timeLayout(td), val)
+	}
+	if t == urlType {
+		return parseURL(val, td)
+	}
+	if t == bigFloatType {
+		return parseBigFloat(val)
+	}
+	if t == reflect.PointerTo(locationType) {
+		return loadLocation(val)
+	}
+	if t == locationType {
+		loc, err := loadLocation(val)
+		if err != nil {
+			return nil, err
+		}
+		return *loc, nil
+	}
+	if isTextUnmarshaler(t) {
+		ptr := reflect.New(t)
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val)); err != nil {
+			return nil, err
+		}
+		return ptr.Elem().Interface(), nil
+	}
+	switch kind := t.Kind(); kind {
 	default:
-		panic("only the types string, int, bool, and float64 are supported")
+		return nil, fmt.Errorf("unsupported type: %s (only string, bool, time.Time, integers, floats, and pointers, slices, and maps of them, as well as implementations of encoding.TextUnmarshaler are supported)", kind)
+	case reflect.Pointer:
+		elem, err := parseValue(t.Elem(), val, td)
+		if err != nil {
+			return nil, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(reflect.ValueOf(elem).Convert(t.Elem()))
+		return ptr.Interface(), nil
+	case reflect.Slice:
+		if isBytes(t) {
+			return decodeBytes(val, td)
+		}
+		return parseSlice(t, val, td)
+	case reflect.Map:
+		return parseMap(t, val, td)
 	case reflect.String:
+		if err := checkPattern(val, td); err != nil {
+			return nil, err
+		}
 		return val, nil
 	case reflect.Int:
-		return strconv.Atoi(val)
-	case reflect.Float64:
-		return strconv.ParseFloat(val, 64)
+		i, err := strconv.Atoi(val)
+		if errors.Is(err, strconv.ErrRange) {
+			return nil, fmt.Errorf("value %s overflows %s", val, t)
+		}
+		return i, err
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(val, 10, t.Bits())
+		if errors.Is(err, strconv.ErrRange) {
+			return nil, fmt.Errorf("value %s overflows %s", val, t)
+		}
+		return i, err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if strings.HasPrefix(val, "-") {
+			return nil, fmt.Errorf("value %s is negative, but %s is unsigned", val, t)
+		}
+		u, err := strconv.ParseUint(val, 10, t.Bits())
+		if errors.Is(err, strconv.ErrRange) {
+			return nil, fmt.Errorf("value %s overflows %s", val, t)
+		}
+		return u, err
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, t.Bits())
+		if errors.Is(err, strconv.ErrRange) {
+			return nil, fmt.Errorf("value %s overflows %s", val, t)
+		}
+		return f, err
 	case reflect.Bool:
-		return wordToBool(val)
+		return td.boolWords().parse(val)
 	}
 }
 
-func wordToBool(word string) (bool, error) {
-	switch strings.ToLower(word) {
-	case "y", "yes", "t", "true", "1":
-		return true, nil
-	case "n", "no", "f", "false", "0":
-		return false, nil
-	default:
-		return false, fmt.Errorf("not a boolean value: %s", word)
+// parseSlice splits val on the separator and parses each element into the
+// element type of the slice type t. Whitespace around elements is trimmed,
+// unless the field is strict.
+func parseSlice(t reflect.Type, val string, td TagData) (any, error) {
+	if t.Elem().Kind() == reflect.Slice {
+		return nil, fmt.Errorf("unsupported type: %s (nested slices are not supported)", t)
+	}
+	parts := strings.Split(val, sliceSep(td))
+	slice := reflect.MakeSlice(t, len(parts), len(parts))
+	for i, part := range parts {
+		elem, err := parseValue(t.Elem(), trimElem(part, td), td)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		slice.Index(i).Set(reflect.ValueOf(elem).Convert(t.Elem()))
+	}
+	return slice.Interface(), nil
+}
+
+// parseMap splits val into pairs on the separator, and each pair into key
+// and value on the key/value separator. Keys and values are parsed into the
+// key and element type of the map type t. Later pairs override earlier ones
+// with the same key.
+func parseMap(t reflect.Type, val string, td TagData) (any, error) {
+	for _, sub := range []reflect.Type{t.Key(), t.Elem()} {
+		if sub.Kind() == reflect.Slice || sub.Kind() == reflect.Map {
+			return nil, fmt.Errorf("unsupported type: %s (nested slices and maps are not supported)", t)
+		}
+	}
+	kvSep := mapKVSep(td)
+	m := reflect.MakeMap(t)
+	for _, pair := range strings.Split(val, sliceSep(td)) {
+		rawKey, rawVal, ok := strings.Cut(pair, kvSep)
+		if !ok {
+			return nil, fmt.Errorf("missing %q in pair: %q", kvSep, pair)
+		}
+		keyTd := td
+		keyTd.Min, keyTd.Max = "", "" // bounds apply to values only
+		key, err := parseValue(t.Key(), trimElem(rawKey, keyTd), keyTd)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", rawKey, err)
+		}
+		elem, err := parseValue(t.Elem(), trimElem(rawVal, td), td)
+		if err != nil {
+			return nil, fmt.Errorf("value of %q: %w", rawKey, err)
+		}
+		elemVal := reflect.ValueOf(elem).Convert(t.Elem())
+		if td.Weighted {
+			if err := checkWeight(elemVal); err != nil {
+				return nil, fmt.Errorf("value of %q: %w", rawKey, err)
+			}
+		}
+		m.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elemVal)
+	}
+	return m.Interface(), nil
+}
+
+// mapKVSep returns the separator between keys and values of map fields.
+func mapKVSep(td TagData) string {
+	if td.KVSep != "" {
+		return td.KVSep
+	}
+	if td.Weighted {
+		return ":"
+	}
+	return "="
+}
+
+// sliceSep returns the separator slice fields and the pairs of map fields
+// are split on.
+func sliceSep(td TagData) string {
+	if td.Sep != "" {
+		return td.Sep
+	}
+	return ","
+}
+
+// parseURL parses val as a URL, whose scheme must be one of the schemes
+// given with the `schemes` property, if any.
+func parseURL(val string, td TagData) (url.URL, error) {
+	u, err := url.Parse(val)
+	if err != nil {
+		return url.URL{}, err
+	}
+	if len(td.Schemes) > 0 && !slices.Contains(td.Schemes, u.Scheme) {
+		return url.URL{}, fmt.Errorf("scheme of %s must be one of: %s", u.Redacted(), strings.Join(td.Schemes, ", "))
 	}
+	return *u, nil
 }
 
-func setUnexportedField(field reflect.Value, value any) {
-	reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Set(reflect.ValueOf(value))
+// loadLocation loads the time zone named val, explaining what went wrong if
+// there is no such zone.
+func loadLocation(val string) (*time.Location, error) {
+	loc, err := time.LoadLocation(val)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q: must be an IANA time zone name like Europe/Zurich, or UTC, or Local", val)
+	}
+	return loc, nil
+}
+
+// parseBigFloat parses val with enough precision to represent all of its
+// digits, instead of the 64 bits big.Float.UnmarshalText uses.
+func parseBigFloat(val string) (big.Float, error) {
+	prec := uint(math.Ceil(float64(len(val)) * math.Log2(10)))
+	f, _, err := big.ParseFloat(val, 10, max(prec, 64), big.ToNearestEven)
+	if err != nil {
+		return big.Float{}, err
+	}
+	return *f, nil
+}
+
+// timeLayout returns the layout time.Time fields are parsed and formatted
+// with.
+func timeLayout(td TagData) string {
+	if td.Layout != "" {
+		return td.Layout
+	}
+	return time.RFC3339
+}
+
+func setField(field reflect.Value, value any) {
+	if lf, ok := asLazy(field); ok {
+		lf.set(reflect.ValueOf(value).Convert(lf.lazyType()).Interface())
+		return
+	}
+	// parseValue returns values of the underlying type, e.g. string for a
+	// field of type `type Level string`
+	value = reflect.ValueOf(value).Convert(field.Type()).Interface()
+	if field.CanSet() {
+		field.Set(reflect.ValueOf(value))
+	} else {
+		setUnexportedField(field, value)
+	}
 }
@@ -0,0 +1,44 @@
+//go:build !parsenv_nounsafe
+
+package parsenv
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadUnexported(t *testing.T) {
+	var myConfig struct {
+		host    string `cfg:"required"`
+		port    int    `cfg:"default=5432"`
+		timeout time.Duration
+		addr    netip.Addr
+		tags    []string
+		limit   *int
+		sidecarConfig
+	}
+	t.Setenv("HOST", "db.internal")
+	t.Setenv("TIMEOUT", "3s")
+	t.Setenv("ADDR", "10.0.0.1")
+	t.Setenv("TAGS", "a,b")
+	t.Setenv("LIMIT", "10")
+	t.Setenv("ENDPOINT", "otel.internal")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.host != "db.internal" || myConfig.port != 5432 || myConfig.timeout != 3*time.Second {
+		t.Errorf("unexpected config: %+v", myConfig)
+	}
+	if myConfig.addr != netip.MustParseAddr("10.0.0.1") || !reflect.DeepEqual(myConfig.tags, []string{"a", "b"}) {
+		t.Errorf("unexpected config: %+v", myConfig)
+	}
+	if myConfig.limit == nil || *myConfig.limit != 10 {
+		t.Errorf("expected a limit of 10, got: %v", myConfig.limit)
+	}
+	if myConfig.Endpoint != "otel.internal" {
+		t.Errorf("expected the field promoted from an unexported struct to be loaded, got: %q", myConfig.Endpoint)
+	}
+}
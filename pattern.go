@@ -0,0 +1,88 @@
+package parsenv
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"sync"
+)
+
+// patternKey identifies a pattern of a field of the struct type t.
+type patternKey struct {
+	t       reflect.Type
+	pattern string
+}
+
+// patterns caches the compiled patterns by struct type, so that they are
+// compiled only once, no matter how often a struct is loaded.
+var patterns sync.Map // map[patternKey]*regexp.Regexp
+
+// compilePattern compiles the `pattern` property of a field of type ft in
+// the struct type t.
+func compilePattern(t, ft reflect.Type, pattern string) (*regexp.Regexp, error) {
+	if unitTarget(ft).Kind() != reflect.String {
+		return nil, fmt.Errorf("pattern is only valid on string fields, or slices of them")
+	}
+	key := patternKey{t, pattern}
+	if re, ok := patterns.Load(key); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	patterns.Store(key, re)
+	return re, nil
+}
+
+// checkPattern checks that val matches the pattern of td, if any.
+func checkPattern(val string, td TagData) error {
+	if td.pattern != nil && !td.pattern.MatchString(val) {
+		return fmt.Errorf("value %s does not match the pattern %s", val, td.pattern)
+	}
+	return nil
+}
+
+// patternExample synthesizes a value matching re, taking the first
+// alternative of each choice and the minimum number of repetitions, e.g. AAA
+// for ^[A-Z]{3}$. ok is false if the value doesn't match after all, e.g.
+// because of a word boundary.
+func patternExample(re *regexp.Regexp) (example string, ok bool) {
+	prog, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var sb strings.Builder
+	var walk func(*syntax.Regexp)
+	walk = func(r *syntax.Regexp) {
+		switch r.Op {
+		case syntax.OpLiteral:
+			sb.WriteString(string(r.Rune))
+		case syntax.OpCharClass:
+			if len(r.Rune) > 0 {
+				sb.WriteRune(r.Rune[0])
+			}
+		case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+			sb.WriteByte('x')
+		case syntax.OpCapture:
+			walk(r.Sub[0])
+		case syntax.OpConcat:
+			for _, sub := range r.Sub {
+				walk(sub)
+			}
+		case syntax.OpAlternate:
+			walk(r.Sub[0])
+		case syntax.OpPlus:
+			walk(r.Sub[0])
+		case syntax.OpRepeat:
+			for range r.Min {
+				walk(r.Sub[0])
+			}
+		}
+	}
+	walk(prog.Simplify())
+	example = sb.String()
+	return example, re.MatchString(example)
+}
@@ -0,0 +1,94 @@
+package parsenv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadPattern(t *testing.T) {
+	type Config struct {
+		Bucket string   `cfg:"pattern=^[a-z0-9-]+$"`
+		Zones  []string `cfg:"pattern=^[a-z]+-[0-9]$"`
+		Region *string  `cfg:"pattern=^eu-;default=eu-west"`
+	}
+	var myConfig Config
+	t.Setenv("BUCKET", "invoices-2024")
+	t.Setenv("ZONES", "zrh-1,gva-2")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.Bucket != "invoices-2024" || len(myConfig.Zones) != 2 || *myConfig.Region != "eu-west" {
+		t.Errorf("expected the values to be loaded, got: %+v", myConfig)
+	}
+
+	t.Setenv("BUCKET", "Invoices_2024")
+	t.Setenv("ZONES", "zrh-1,gva")
+	err := Load(&myConfig)
+	for _, expected := range []string{
+		"value Invoices_2024 does not match the pattern ^[a-z0-9-]+$",
+		"element 1: value gva does not match the pattern ^[a-z]+-[0-9]$",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q in the error, got: %v", expected, err)
+		}
+	}
+
+	if _, ok := patterns.Load(patternKey{reflect.TypeFor[Config](), "^[a-z0-9-]+$"}); !ok {
+		t.Error("expected the pattern to be cached for the struct type")
+	}
+}
+
+func TestPatternTagErrors(t *testing.T) {
+	for _, cfg := range []any{
+		&struct {
+			Port int `cfg:"pattern=^[0-9]+$"`
+		}{},
+		&struct {
+			Name string `cfg:"pattern=[a-z"`
+		}{},
+		&struct {
+			Name string `cfg:"pattern=^[a-z]+$;default=Bob"`
+		}{},
+	} {
+		if err := Load(cfg); !errors.As(err, new(*TagError)) {
+			t.Errorf("expected a *TagError for %T, got: %v", cfg, err)
+		}
+	}
+}
+
+func TestPatternExample(t *testing.T) {
+	var myConfig struct {
+		Currency string   `cfg:"pattern=^[A-Z]{3}$"`
+		Region   string   `cfg:"pattern=^(eu|us)-[a-z]+-\\d$"`
+		Tags     []string `cfg:"pattern=^v\\d+\\.\\d+$"`
+		Bucket   string   `cfg:"pattern=^[a-z]+$"`
+	}
+	infos, err := Describe(&myConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"AAA", "eu-a-0", "v0.0,v0.0", "example"}
+	l := MapLookuper{}
+	for i, fi := range infos {
+		if example := fi.Example(); example != expected[i] {
+			t.Errorf("%s: expected %s, got: %s", fi.Name, expected[i], example)
+		}
+		l[fi.Name] = fi.Example()
+	}
+	if err := Load(&myConfig, WithLookuper(l)); err != nil {
+		t.Errorf("expected the examples to load, got: %v", err)
+	}
+
+	var invalid struct {
+		Word  string `cfg:"pattern=^a\\bb$"`
+		Given string `cfg:"pattern=^a\\bb$;example=never"`
+	}
+	_, err = Describe(&invalid)
+	var terr *TagError
+	if !errors.As(err, &terr) || terr.Field != "Word" || !strings.Contains(err.Error(), "example=") {
+		t.Errorf("expected a *TagError asking for an example for Word only, got: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package parsenv
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// PresetHeroku configures Load for apps running on Heroku. On top of the
+// variables of the configured Lookuper, it provides
+//
+//   - platform-independent names for the platform's own variables:
+//     APP_NAME (HEROKU_APP_NAME), INSTANCE_ID (DYNO), and GIT_COMMIT
+//     (SOURCE_VERSION or HEROKU_SLUG_COMMIT),
+//   - the platform's default for PORT, if it is not set, and
+//   - the components of connection URLs like DATABASE_URL, decomposed into
+//     DATABASE_HOST, DATABASE_PORT, DATABASE_USER, DATABASE_PASSWORD, and
+//     DATABASE_NAME (likewise for REDIS_URL), unless those are set
+//     explicitly.
+//
+// Presets wrap the configured Lookuper, regardless of the order of the
+// options:
+//
+//	err := parsenv.Load(&cfg, parsenv.PresetHeroku(), parsenv.WithLookuper(l))
+func PresetHeroku() Option {
+	return withPreset(platformPreset{
+		aliases: map[string][]string{
+			"APP_NAME":    {"HEROKU_APP_NAME"},
+			"INSTANCE_ID": {"DYNO"},
+			"GIT_COMMIT":  {"SOURCE_VERSION", "HEROKU_SLUG_COMMIT"},
+		},
+		defaults: map[string]string{"PORT": "5000"},
+		urls:     []string{"DATABASE", "REDIS"},
+	})
+}
+
+// PresetRender is like PresetHeroku, but for services running on Render.
+// It provides APP_NAME (RENDER_SERVICE_NAME), INSTANCE_ID
+// (RENDER_INSTANCE_ID), GIT_COMMIT (RENDER_GIT_COMMIT), and PUBLIC_URL
+// (RENDER_EXTERNAL_URL).
+func PresetRender() Option {
+	return withPreset(platformPreset{
+		aliases: map[string][]string{
+			"APP_NAME":    {"RENDER_SERVICE_NAME"},
+			"INSTANCE_ID": {"RENDER_INSTANCE_ID"},
+			"GIT_COMMIT":  {"RENDER_GIT_COMMIT"},
+			"PUBLIC_URL":  {"RENDER_EXTERNAL_URL"},
+		},
+		defaults: map[string]string{"PORT": "10000"},
+		urls:     []string{"DATABASE", "REDIS"},
+	})
+}
+
+// PresetFly is like PresetHeroku, but for apps running on Fly.io.
+// It provides APP_NAME (FLY_APP_NAME), INSTANCE_ID (FLY_MACHINE_ID or
+// FLY_ALLOC_ID), and REGION (FLY_REGION).
+func PresetFly() Option {
+	return withPreset(platformPreset{
+		aliases: map[string][]string{
+			"APP_NAME":    {"FLY_APP_NAME"},
+			"INSTANCE_ID": {"FLY_MACHINE_ID", "FLY_ALLOC_ID"},
+			"REGION":      {"FLY_REGION"},
+		},
+		defaults: map[string]string{"PORT": "8080"},
+		urls:     []string{"DATABASE", "REDIS"},
+	})
+}
+
+// urlComponents are the components of connection URLs provided by presets.
+var urlComponents = []string{"HOST", "PORT", "USER", "PASSWORD", "NAME"}
+
+// platformPreset is a Source implementing the conventions of a platform.
+type platformPreset struct {
+	base     Lookuper
+	aliases  map[string][]string
+	defaults map[string]string
+	urls     []string
+}
+
+func withPreset(p platformPreset) Option {
+	return func(o *Options) {
+		o.presets = append(o.presets, p)
+	}
+}
+
+// Snapshot snapshots the wrapped Lookuper.
+func (p platformPreset) Snapshot() (Lookuper, error) {
+	base, err := snapshot(p.base)
+	if err != nil {
+		return nil, err
+	}
+	p.base = base
+	return p, nil
+}
+
+func (p platformPreset) Lookup(name string) (string, bool) {
+	val, ok, _ := p.LookupErr(name)
+	return val, ok
+}
+
+func (p platformPreset) LookupErr(name string) (string, bool, error) {
+	if val, ok, err := lookupErr(p.base, name); err != nil || ok {
+		return val, ok, err
+	}
+	for _, alias := range p.aliases[name] {
+		if val, ok, err := lookupErr(p.base, alias); err != nil || ok {
+			return val, ok, err
+		}
+	}
+	if val, ok := p.defaults[name]; ok {
+		return val, true, nil
+	}
+	for _, prefix := range p.urls {
+		component, found := strings.CutPrefix(name, prefix+"_")
+		if !found || !slices.Contains(urlComponents, component) {
+			continue
+		}
+		rawURL, ok, err := lookupErr(p.base, prefix+"_URL")
+		if err != nil || !ok {
+			return "", false, err
+		}
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			// the URL (and so the error) may contain a password
+			return "", false, fmt.Errorf("%s_URL is not a valid URL", prefix)
+		}
+		var val string
+		switch component {
+		case "HOST":
+			val = u.Hostname()
+		case "PORT":
+			val = u.Port()
+		case "USER":
+			val = u.User.Username()
+		case "PASSWORD":
+			val, _ = u.User.Password()
+		case "NAME":
+			val = strings.TrimPrefix(u.Path, "/")
+		}
+		return val, val != "", nil
+	}
+	return "", false, nil
+}
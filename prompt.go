@@ -0,0 +1,95 @@
+package parsenv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// A Prompter asks the user for the value of a variable. It is used by Load
+// for required variables that are missing, if Options.Prompter is set.
+type Prompter interface {
+	// Prompt asks for the value of the variable name. If secret is true, the
+	// input must not be echoed. Returning an error or an empty value makes
+	// Load report the variable as missing.
+	Prompt(name string, secret bool) (string, error)
+}
+
+// ErrNotTerminal is returned by TerminalPrompter if its input is not a
+// terminal.
+var ErrNotTerminal = errors.New("not a terminal")
+
+// TerminalPrompter prompts for values on a terminal. If In is not a terminal,
+// for example because the program runs as a service, Prompt fails with
+// ErrNotTerminal, so that Load reports the variable as missing as usual.
+type TerminalPrompter struct {
+	In  *os.File  // the terminal to read from
+	Out io.Writer // where to write the prompt to
+}
+
+// Prompt writes "NAME: " to Out and reads a line from In.
+// Input of secret values is hidden (not supported on all platforms).
+func (p TerminalPrompter) Prompt(name string, secret bool) (string, error) {
+	if !isTerminal(p.In) {
+		return "", ErrNotTerminal
+	}
+	if _, err := fmt.Fprintf(p.Out, "%s: ", name); err != nil {
+		return "", err
+	}
+	if secret {
+		if err := setEcho(p.In, false); err != nil {
+			fmt.Fprintln(p.Out)
+			return "", err
+		}
+		defer func() {
+			setEcho(p.In, true)
+			fmt.Fprintln(p.Out)
+		}()
+	}
+	return readLine(p.In)
+}
+
+func isTerminal(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// readLine reads a single line from r, byte by byte, so that no input is
+// consumed beyond the end of the line.
+func readLine(r io.Reader) (string, error) {
+	var line strings.Builder
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			line.WriteByte(buf[0])
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSuffix(line.String(), "\r"), nil
+}
+
+// prompt asks opts.Prompter for a value, if prompting is enabled.
+func prompt(name string, td TagData, opts Options) (string, bool) {
+	if opts.Prompter == nil {
+		return "", false
+	}
+	val, err := opts.Prompter.Prompt(name, td.Secret)
+	if err != nil || val == "" {
+		return "", false
+	}
+	return val, true
+}
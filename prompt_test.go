@@ -0,0 +1,78 @@
+package parsenv
+
+import (
+	"io"
+	"os"
+	"testing"
+)
+
+type fakePrompter struct {
+	values  map[string]string
+	secrets []string
+}
+
+func (p *fakePrompter) Prompt(name string, secret bool) (string, error) {
+	if secret {
+		p.secrets = append(p.secrets, name)
+	}
+	return p.values[name], nil
+}
+
+func TestLoadPrompt(t *testing.T) {
+	var myConfig struct {
+		User     string `cfg:"required"`
+		Password string `cfg:"required;secret"`
+		Host     string `cfg:"required"`
+	}
+	p := &fakePrompter{values: map[string]string{
+		"USER":     "admin",
+		"PASSWORD": "hunter2",
+	}}
+	t.Setenv("USER", "")
+
+	err := Load(&myConfig, WithPrompter(p))
+	if len(ErrorDetails(err)) != 1 {
+		t.Errorf("expected HOST to be reported missing, got: %v", err)
+	}
+	if myConfig.User != "admin" || myConfig.Password != "hunter2" {
+		t.Errorf("unexpected config: %#v", myConfig)
+	}
+	if len(p.secrets) != 1 || p.secrets[0] != "PASSWORD" {
+		t.Errorf("expected only PASSWORD to be prompted for as secret, got: %v", p.secrets)
+	}
+}
+
+func TestTerminalPrompterNotATerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	w.Write([]byte("value\n"))
+	w.Close()
+
+	p := TerminalPrompter{In: r, Out: io.Discard}
+	if _, err := p.Prompt("FOO", false); err != ErrNotTerminal {
+		t.Errorf("expected ErrNotTerminal, got: %v", err)
+	}
+}
+
+func TestReadLine(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	w.Write([]byte("first\r\nsecond\n"))
+	w.Close()
+
+	for _, expected := range []string{"first", "second", ""} {
+		line, err := readLine(r)
+		if err != nil {
+			t.Error(err)
+		}
+		if line != expected {
+			t.Errorf("expected %q, got: %q", expected, line)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package parsenv
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+)
+
+// checkBounds checks that the bounds given with `min` and `max` are valid
+// values of the numeric type of fields of type t, and that min is not
+// greater than max.
+func checkBounds(t reflect.Type, td TagData) error {
+	target := unitTarget(t)
+	if !isUnitKind(target.Kind()) {
+		return fmt.Errorf("min and max are only valid on numeric fields")
+	}
+	lo, hi := td.Min, td.Max
+	td.Min, td.Max = "", ""
+	var bounds [2]any
+	for i, bound := range []struct{ name, val string }{{"min", lo}, {"max", hi}} {
+		if bound.val == "" {
+			continue
+		}
+		v, err := parseValue(target, bound.val, td)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", bound.name, err)
+		}
+		bounds[i] = v
+	}
+	if lo != "" && hi != "" && compareNumbers(bounds[0], bounds[1]) > 0 {
+		return fmt.Errorf("min %s is greater than max %s", lo, hi)
+	}
+	return nil
+}
+
+// parseInRange parses val like parseValue, and checks that the result lies
+// within the bounds given with `min` and `max` (inclusive).
+func parseInRange(t reflect.Type, val string, td TagData) (any, error) {
+	lo, hi := td.Min, td.Max
+	td.Min, td.Max = "", ""
+	v, err := parseValue(t, val, td)
+	if err != nil {
+		return nil, err
+	}
+	if lo != "" {
+		bound, err := parseValue(t, lo, td)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min: %w", err)
+		}
+		if compareNumbers(v, bound) < 0 {
+			return nil, fmt.Errorf("value %s is less than the minimum %s", val, lo)
+		}
+	}
+	if hi != "" {
+		bound, err := parseValue(t, hi, td)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max: %w", err)
+		}
+		if compareNumbers(v, bound) > 0 {
+			return nil, fmt.Errorf("value %s is greater than the maximum %s", val, hi)
+		}
+	}
+	return v, nil
+}
+
+// compareNumbers compares two numbers of the same class (signed integers,
+// unsigned integers, or floats), like cmp.Compare.
+func compareNumbers(a, b any) int {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch va.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(va.Int(), vb.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(va.Uint(), vb.Uint())
+	default:
+		return cmp.Compare(va.Float(), vb.Float())
+	}
+}
@@ -0,0 +1,93 @@
+package parsenv
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadMinMax(t *testing.T) {
+	var myConfig struct {
+		Port    int           `cfg:"min=1;max=65535"`
+		Workers uint          `cfg:"min=1;default=4"`
+		Ratio   float64       `cfg:"max=1"`
+		Timeout time.Duration `cfg:"min=1s;max=5m"`
+		Cache   ByteSize      `cfg:"max=1GiB"`
+		Shards  []int         `cfg:"min=0;max=15"`
+	}
+	t.Setenv("PORT", "8080")
+	t.Setenv("RATIO", "0.5")
+	t.Setenv("TIMEOUT", "30s")
+	t.Setenv("CACHE", "512MiB")
+	t.Setenv("SHARDS", "0,15")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.Port != 8080 || myConfig.Workers != 4 || myConfig.Timeout != 30*time.Second || myConfig.Cache != 512<<20 {
+		t.Errorf("expected the values to be loaded, got: %+v", myConfig)
+	}
+
+	for name, val := range map[string]string{
+		"PORT":    "70000",
+		"WORKERS": "0",
+		"RATIO":   "1.5",
+		"TIMEOUT": "500ms",
+		"CACHE":   "2GiB",
+		"SHARDS":  "3,16",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, val)
+			err := Load(&myConfig)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected a *ParseError, got: %v", err)
+			}
+			if perr.Name != name || !strings.Contains(perr.Error(), "than the m") {
+				t.Errorf("expected an out of range error for %s, got: %v", name, perr)
+			}
+		})
+	}
+}
+
+func TestMinMaxTagErrors(t *testing.T) {
+	for _, cfg := range []any{
+		&struct {
+			Name string `cfg:"min=1"`
+		}{},
+		&struct {
+			Port int `cfg:"min=10;max=1"`
+		}{},
+		&struct {
+			Port int `cfg:"max=many"`
+		}{},
+		&struct {
+			Port int `cfg:"max=10;default=11"`
+		}{},
+	} {
+		if err := Load(cfg); !errors.As(err, new(*TagError)) {
+			t.Errorf("expected a *TagError for %T, got: %v", cfg, err)
+		}
+	}
+}
+
+func TestLoadDuration(t *testing.T) {
+	var myConfig struct {
+		Timeout time.Duration `cfg:"default=1m30s"`
+	}
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.Timeout != 90*time.Second {
+		t.Errorf("expected 1m30s, got: %s", myConfig.Timeout)
+	}
+	env, err := CommandEnv(&myConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(env, "TIMEOUT=1m30s") {
+		t.Errorf("expected TIMEOUT=1m30s, got: %v", env)
+	}
+}
@@ -0,0 +1,96 @@
+package parsenv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate is a number of events per duration, like a rate limit. It is written
+// as "100/s", where the unit is one of s, m, h, or d, or any duration
+// understood by time.ParseDuration ("5/10s", "1/500ms").
+//
+// parsenv has no dependencies, so there is no method returning a
+// golang.org/x/time/rate.Limit, but PerSecond converts to one directly:
+//
+//	var cfg struct {
+//		ApiRate parsenv.Rate `cfg:"default=100/s"`
+//	}
+//
+//	limiter := rate.NewLimiter(rate.Limit(cfg.ApiRate.PerSecond()), cfg.ApiRate.Count)
+type Rate struct {
+	Count int           // number of events
+	Per   time.Duration // duration in which Count events happen
+}
+
+// rateUnits are the units that may be used without a number, as in "100/m".
+var rateUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+}
+
+// ParseRate parses a Rate, see Rate for the format.
+func ParseRate(s string) (Rate, error) {
+	var r Rate
+	rawCount, rawPer, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return r, fmt.Errorf("invalid rate %q: must be like 100/s", s)
+	}
+	count, err := strconv.Atoi(rawCount)
+	if err != nil || count < 0 {
+		return r, fmt.Errorf("invalid rate %q: count must be a non-negative integer", s)
+	}
+	per, ok := rateUnits[rawPer]
+	if !ok {
+		per, err = time.ParseDuration(rawPer)
+		if err != nil || per <= 0 {
+			return r, fmt.Errorf("invalid rate %q: unit must be s, m, h, d, or a positive duration like 10s", s)
+		}
+	}
+	return Rate{Count: count, Per: per}, nil
+}
+
+// PerSecond returns the number of events per second.
+func (r Rate) PerSecond() float64 {
+	if r.Per <= 0 {
+		return 0
+	}
+	return float64(r.Count) / r.Per.Seconds()
+}
+
+// Interval returns the average time between two events, or 0 if Count is 0.
+func (r Rate) Interval() time.Duration {
+	if r.Count <= 0 {
+		return 0
+	}
+	return r.Per / time.Duration(r.Count)
+}
+
+// String formats the rate like ParseRate expects it.
+func (r Rate) String() string {
+	for unit, per := range rateUnits {
+		if r.Per == per {
+			return strconv.Itoa(r.Count) + "/" + unit
+		}
+	}
+	return strconv.Itoa(r.Count) + "/" + r.Per.String()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so that Load can parse
+// Rate fields.
+func (r *Rate) UnmarshalText(text []byte) error {
+	parsed, err := ParseRate(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r Rate) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
@@ -0,0 +1,61 @@
+package parsenv
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLoadRate(t *testing.T) {
+	var myConfig struct {
+		ApiRate    Rate
+		LoginRate  Rate `cfg:"default=5000/m"`
+		BurstRate  Rate `cfg:"default=1/500ms"`
+		NightlyJob Rate `cfg:"default=2/d"`
+	}
+	t.Setenv("API_RATE", "100/s")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name      string
+		rate      Rate
+		expected  Rate
+		perSecond float64
+		interval  time.Duration
+	}{
+		{"API_RATE", myConfig.ApiRate, Rate{100, time.Second}, 100, 10 * time.Millisecond},
+		{"LOGIN_RATE", myConfig.LoginRate, Rate{5000, time.Minute}, 5000.0 / 60, 12 * time.Millisecond},
+		{"BURST_RATE", myConfig.BurstRate, Rate{1, 500 * time.Millisecond}, 2, 500 * time.Millisecond},
+		{"NIGHTLY_JOB", myConfig.NightlyJob, Rate{2, 24 * time.Hour}, 2.0 / 86400, 12 * time.Hour},
+	} {
+		if tc.rate != tc.expected {
+			t.Errorf("expected %s to be %v, got: %v", tc.name, tc.expected, tc.rate)
+		}
+		if tc.rate.PerSecond() != tc.perSecond {
+			t.Errorf("expected %s to be %v per second, got: %v", tc.name, tc.perSecond, tc.rate.PerSecond())
+		}
+		if tc.rate.Interval() != tc.interval {
+			t.Errorf("expected %s to have an interval of %s, got: %s", tc.name, tc.interval, tc.rate.Interval())
+		}
+	}
+
+	env, err := CommandEnv(&myConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"API_RATE=100/s", "LOGIN_RATE=5000/m", "BURST_RATE=1/500ms", "NIGHTLY_JOB=2/d"}; !slices.Equal(env, expected) {
+		t.Errorf("expected %v, got: %v", expected, env)
+	}
+
+	for _, invalid := range []string{"100", "-1/s", "100/w", "100/0s", "many/s"} {
+		t.Setenv("API_RATE", invalid)
+		err := Load(&myConfig)
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Name != "API_RATE" {
+			t.Errorf("expected a *ParseError for %q, got: %v", invalid, err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package parsenv
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// A Reloader holds a configuration of type T (a struct), which is replaced
+// as a whole when it is reloaded, typically from the onChange callback of a
+// watched source:
+//
+//	src := &parsenv.ConfigServiceSource{Client: client, Prefix: "billing/"}
+//	rl := &parsenv.Reloader[Config]{
+//		Options: []parsenv.Option{parsenv.WithLookuper(src)},
+//		OnReject: func(report *parsenv.Report, err error) {
+//			slog.Error("rejected config change", "err", err, "config", report.Summary())
+//		},
+//	}
+//	if err := rl.Reload(); err != nil {
+//		log.Fatal(err)
+//	}
+//	go src.Watch(ctx, func() { rl.Reload() })
+//	...
+//	cfg := rl.Current()
+//
+// Each reload loads a new snapshot into a fresh value, and only if all
+// fields were loaded and validated without errors is it swapped in. If the
+// Lookuper (and Options.Secrets) is a Snapshotter, like ConfigServiceSource,
+// HTTPSource, RedisHash, and SQLSource, or MultiLookuper and Rollout
+// wrapping them, all fields are loaded from a single snapshot of it, so
+// updates arriving during a reload can't mix in. Thus, when several variables change
+// together, the application sees either all of the new values or none of
+// them, never a half-updated struct.
+//
+// Fields tagged `unset` are removed from the process environment by the
+// first load that reads them from there, so later reloads find them unset
+// and fall back to their default, or reject the snapshot if they are
+// required. Load such fields from Options.Secrets or Options.Lookuper
+// instead, which `unset` leaves alone.
+//
+// The snapshot returned by Current is shared and must not be modified.
+// A Reloader is safe for concurrent use.
+type Reloader[T any] struct {
+	// Options are passed to Load. Options.Report is overwritten.
+	Options []Option
+
+	// Validate, if set, is called with each snapshot that loaded without
+	// errors. If it returns an error, the snapshot is rejected.
+	Validate func(*T) error
+
+	// OnReject, if set, is called with the report and error of each
+	// snapshot that was rejected.
+	OnReject func(report *Report, err error)
+
+	mu      sync.Mutex // serializes reloads
+	current atomic.Pointer[T]
+}
+
+// Current returns the current snapshot, or nil if no snapshot was loaded
+// successfully yet.
+func (r *Reloader[T]) Current() *T {
+	return r.current.Load()
+}
+
+// Reload loads a new snapshot and swaps it in, if it is valid. Otherwise,
+// the current snapshot is kept, and the error is returned (and passed to
+// OnReject).
+func (r *Reloader[T]) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var report Report
+	snapshot := new(T)
+	opts := makeOptions(r.Options)
+	opts.Report = &report
+	err := snapshotLookuper(&opts)
+	if err == nil {
+		err = newLoadError(loadStruct(reflect.ValueOf(snapshot).Elem(), opts), opts)
+	}
+	if err == nil && r.Validate != nil {
+		err = r.Validate(snapshot)
+	}
+	if err != nil {
+		if r.OnReject != nil {
+			r.OnReject(&report, err)
+		}
+		return err
+	}
+	r.current.Store(snapshot)
+	return nil
+}
+
+// snapshotLookuper replaces the Lookuper and Secrets of opts by snapshots
+// of them, if they are Snapshotters.
+func snapshotLookuper(opts *Options) error {
+	var err error
+	if opts.Lookuper != nil {
+		if opts.Lookuper, err = snapshot(opts.Lookuper); err != nil {
+			return err
+		}
+	}
+	if opts.Secrets != nil {
+		if opts.Secrets, err = snapshot(opts.Secrets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package parsenv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestReloader(t *testing.T) {
+	type Config struct {
+		Host string `cfg:"required"`
+		Port int    `cfg:"min=1;max=65535"`
+	}
+	src := MapLookuper{"HOST": "db-1", "PORT": "5432"}
+	var rejected []error
+	rl := &Reloader[Config]{
+		Options: []Option{WithLookuper(src)},
+		Validate: func(cfg *Config) error {
+			if cfg.Host == "localhost" {
+				return errors.New("localhost is not allowed")
+			}
+			return nil
+		},
+		OnReject: func(report *Report, err error) {
+			if len(report.Fields) != 2 {
+				t.Errorf("expected a report of both fields, got: %+v", report.Fields)
+			}
+			rejected = append(rejected, err)
+		},
+	}
+	if rl.Current() != nil {
+		t.Error("expected no snapshot before the first reload")
+	}
+	if err := rl.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	first := rl.Current()
+	if *first != (Config{Host: "db-1", Port: 5432}) {
+		t.Errorf("expected the first snapshot, got: %+v", first)
+	}
+
+	src["HOST"] = "db-2"
+	src["PORT"] = "70000"
+	if err := rl.Reload(); err == nil {
+		t.Error("expected an error for an invalid snapshot, got nil")
+	}
+	src["HOST"] = "localhost"
+	src["PORT"] = "6432"
+	if err := rl.Reload(); err == nil {
+		t.Error("expected an error for a snapshot failing validation, got nil")
+	}
+	if rl.Current() != first || *first != (Config{Host: "db-1", Port: 5432}) {
+		t.Errorf("expected the first snapshot to be kept unchanged, got: %+v", rl.Current())
+	}
+	if len(rejected) != 2 {
+		t.Errorf("expected two rejected snapshots, got: %v", rejected)
+	}
+
+	src["HOST"] = "db-2"
+	if err := rl.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if *rl.Current() != (Config{Host: "db-2", Port: 6432}) {
+		t.Errorf("expected the new snapshot, got: %+v", rl.Current())
+	}
+}
+
+func TestReloaderUnset(t *testing.T) {
+	type Config struct {
+		ApiKey string `cfg:"required;secret;unset"`
+	}
+	t.Setenv("API_KEY", "hunter2")
+	rl := &Reloader[Config]{}
+	if err := rl.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	var missing *MissingError
+	if err := rl.Reload(); !errors.As(err, &missing) {
+		t.Errorf("expected the unset variable to be missing on reload, got: %v", err)
+	}
+	if rl.Current().ApiKey != "hunter2" {
+		t.Errorf("expected the first snapshot to be kept, got: %+v", rl.Current())
+	}
+
+	t.Setenv("API_KEY", "hunter2")
+	rl = &Reloader[Config]{Options: []Option{WithSecrets(MapLookuper{"API_KEY": "hunter3"})}}
+	for range 2 {
+		if err := rl.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rl.Current().ApiKey != "hunter3" || os.Getenv("API_KEY") != "hunter2" {
+		t.Errorf("expected secrets to be reloaded without unsetting API_KEY, got: %+v", rl.Current())
+	}
+}
+
+func TestReloaderSnapshot(t *testing.T) {
+	type Config struct {
+		Host     string
+		Port     int
+		Password string `cfg:"secret"`
+	}
+	for name, wrap := range map[string]func(Lookuper) Lookuper{
+		"source":  func(src Lookuper) Lookuper { return src },
+		"multi":   func(src Lookuper) Lookuper { return MultiLookuper(MapLookuper{"REGION": "eu"}, src) },
+		"rollout": func(src Lookuper) Lookuper { return &Rollout{Source: src, Instance: "a"} },
+	} {
+		client := &fakeConfigService{values: map[string]string{
+			"billing/HOST":     "db-1",
+			"billing/PORT":     "5432",
+			"billing/PASSWORD": "hunter1",
+		}}
+		src := &ConfigServiceSource{Client: client, Prefix: "billing/"}
+		if err := src.Fetch(context.Background(), "HOST", "PORT", "PASSWORD", "HOST_ROLLOUT", "PORT_ROLLOUT"); err != nil {
+			t.Fatal(err)
+		}
+		update := map[string]string{"billing/HOST": "db-2", "billing/PORT": "6432", "billing/PASSWORD": "hunter2"}
+		// streams the update into the source right after HOST was looked up
+		interleave := func(next Decoder) Decoder {
+			return func(fi FieldInfo, val string) (any, error) {
+				if fi.Name == "HOST" && update != nil {
+					client.updates = make(chan map[string]string, 1)
+					client.updates <- update
+					close(client.updates)
+					update = nil
+					src.Watch(context.Background(), nil)
+				}
+				return next(fi, val)
+			}
+		}
+		rl := &Reloader[Config]{Options: []Option{WithLookuper(wrap(src)), WithSecrets(src), WithMiddleware(interleave)}}
+		if err := rl.Reload(); err != nil {
+			t.Fatal(err)
+		}
+		if *rl.Current() != (Config{Host: "db-1", Port: 5432, Password: "hunter1"}) {
+			t.Errorf("%s: expected the values from before the update, got: %+v", name, rl.Current())
+		}
+		if err := rl.Reload(); err != nil {
+			t.Fatal(err)
+		}
+		if *rl.Current() != (Config{Host: "db-2", Port: 6432, Password: "hunter2"}) {
+			t.Errorf("%s: expected the values from after the update, got: %+v", name, rl.Current())
+		}
+	}
+}
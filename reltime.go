@@ -0,0 +1,48 @@
+package parsenv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// relativeTime resolves the default of a time.Time field relative to now:
+// now, now+<period>, or now-<period>, where the period is a duration as
+// understood by time.ParseDuration, or a number of days like 7d.
+// The boolean is false if def is not of that form, or t is not a time.Time
+// (or a pointer to one).
+func relativeTime(t reflect.Type, def string, now time.Time) (time.Time, bool, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	offset, ok := strings.CutPrefix(def, "now")
+	if !ok || t != timeType {
+		return time.Time{}, false, nil
+	}
+	if offset == "" {
+		return now, true, nil
+	}
+	sign := time.Duration(1)
+	switch offset[0] {
+	case '+':
+	case '-':
+		sign = -1
+	default:
+		return time.Time{}, false, nil
+	}
+	d, err := parsePeriod(offset[1:])
+	if err != nil {
+		return time.Time{}, true, fmt.Errorf("invalid relative time %q: %w", def, err)
+	}
+	return now.Add(sign * d), true, nil
+}
+
+// defaultValue returns the default value of the field, with relative times
+// resolved against Options.Now and formatted with the layout of the field.
+func defaultValue(spec fieldSpec, opts Options) string {
+	if t, ok, err := relativeTime(spec.field.Type, spec.tag.Default, opts.now()); ok && err == nil {
+		return t.Format(timeLayout(spec.tag))
+	}
+	return spec.tag.Default
+}
@@ -0,0 +1,60 @@
+package parsenv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadRelativeDefault(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	var myConfig struct {
+		Start   time.Time  `cfg:"default=now"`
+		Expires *time.Time `cfg:"default=now+24h"`
+		Since   time.Time  `cfg:"default=now-7d;layout=2006-01-02"`
+		Set     time.Time  `cfg:"default=now+1h"`
+	}
+	l := MapLookuper{"SET": "2000-01-01T00:00:00Z"}
+
+	if err := Load(&myConfig, WithLookuper(l), WithNow(func() time.Time { return now })); err != nil {
+		t.Fatal(err)
+	}
+	if !myConfig.Start.Equal(now) {
+		t.Errorf("expected %v, got: %v", now, myConfig.Start)
+	}
+	if myConfig.Expires == nil || !myConfig.Expires.Equal(now.Add(24*time.Hour)) {
+		t.Errorf("expected %v, got: %v", now.Add(24*time.Hour), myConfig.Expires)
+	}
+	if expected := time.Date(2024, time.February, 23, 0, 0, 0, 0, time.UTC); !myConfig.Since.Equal(expected) {
+		t.Errorf("expected %v, got: %v", expected, myConfig.Since)
+	}
+	if expected := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC); !myConfig.Set.Equal(expected) {
+		t.Errorf("expected the variable to win over the default, got: %v", myConfig.Set)
+	}
+
+	var invalid struct {
+		Until time.Time `cfg:"default=now+soon"`
+	}
+	err := Load(&invalid, WithLookuper(MapLookuper{}))
+	if err == nil || !strings.Contains(err.Error(), `invalid relative time "now+soon"`) {
+		t.Errorf("expected an error about the relative time, got: %v", err)
+	}
+
+	// the default is checked against Options.Now, not the wall clock
+	var farFuture struct {
+		Since time.Time `cfg:"default=now;layout=2006-01-02"`
+	}
+	var terr *TagError
+	err = Load(&farFuture, WithLookuper(MapLookuper{}), WithNow(func() time.Time { return time.Date(10000, time.January, 1, 0, 0, 0, 0, time.UTC) }))
+	if !errors.As(err, &terr) {
+		t.Errorf("expected a *TagError for a default the layout can't hold, got: %v", err)
+	}
+
+	var notTime struct {
+		Name string `cfg:"default=now+1h"`
+	}
+	if err := Load(&notTime, WithLookuper(MapLookuper{})); err != nil || notTime.Name != "now+1h" {
+		t.Errorf("expected strings to be left alone, got: %q, %v", notTime.Name, err)
+	}
+}
@@ -0,0 +1,162 @@
+package parsenv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// redacted replaces the values of secret fields.
+const redacted = "[REDACTED]"
+
+// ValueSource tells where the value of a field came from.
+type ValueSource int
+
+const (
+	SourceUnset   ValueSource = iota // no value was found, the field is left as is
+	SourceEnv                        // the value was found by the Lookuper (or FlagProvider)
+	SourceDefault                    // the default value of the `cfg` tag was used
+	SourcePrompt                     // the value was entered at a prompt
+)
+
+func (s ValueSource) String() string {
+	switch s {
+	case SourceUnset:
+		return "unset"
+	case SourceEnv:
+		return "env"
+	case SourceDefault:
+		return "default"
+	case SourcePrompt:
+		return "prompt"
+	default:
+		return fmt.Sprintf("ValueSource(%d)", int(s))
+	}
+}
+
+// FieldReport tells how a single field was loaded.
+type FieldReport struct {
+	FieldInfo
+	Source ValueSource
+
+	// Value is the effective value of the field after loading, formatted
+	// like CommandEnv does, or "[REDACTED]" for fields tagged `secret`.
+	Value string
+
+	// Hash is the hex encoded SHA-256 of the effective value, or empty if
+	// the field is unset or tagged `secret` (an unsalted hash of a secret
+	// could be reversed by guessing). It tells whether the value changed,
+	// without revealing it, see Report.Audit.
+	Hash string
+}
+
+// A Report records how Load populated each field, see WithReport.
+//
+//	var report parsenv.Report
+//	if err := parsenv.Load(&cfg, parsenv.WithReport(&report)); err != nil {
+//		log.Fatal(err)
+//	}
+//	log.Print(report.Summary())
+type Report struct {
+	// Fields in the order Load visited them. Ignored and unloadable fields
+	// are omitted.
+	Fields []FieldReport
+
+	// Time when Load populated the report, according to Options.Now.
+	Time time.Time
+}
+
+// Summary describes at a glance how much of the configuration was set
+// explicitly, and which variables tagged `recommended` are missing:
+//
+//	12 variables: 7 set, 3 using defaults, 2 optional unset
+//	missing recommended variables: SENTRY_DSN, LOG_LEVEL
+func (r *Report) Summary() string {
+	var set, defaults, unset int
+	var missing []string
+	for _, f := range r.Fields {
+		switch f.Source {
+		case SourceEnv, SourcePrompt:
+			set++
+		case SourceDefault:
+			defaults++
+		case SourceUnset:
+			if !f.Tag.Required {
+				unset++
+			}
+			if f.Tag.Recommended {
+				missing = append(missing, f.Name)
+			}
+		}
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d variables: %d set, %d using defaults, %d optional unset", len(r.Fields), set, defaults, unset)
+	if len(missing) > 0 {
+		fmt.Fprintf(&sb, "\nmissing recommended variables: %s", strings.Join(missing, ", "))
+	}
+	return sb.String()
+}
+
+// Fingerprint returns a stable hash of the effective configuration, i.e. of
+// the names and values of all fields. Services can log it at startup to
+// detect instances running with diverging configuration. Since secret values
+// are redacted, changing only those doesn't change the fingerprint.
+func (r *Report) Fingerprint() string {
+	lines := make([]string, len(r.Fields))
+	for i, f := range r.Fields {
+		lines[i] = f.Name + "=" + f.Value + "\n"
+	}
+	slices.Sort(lines)
+	h := sha256.New()
+	for _, line := range lines {
+		h.Write([]byte(line))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// record adds the field described by spec, with its value val, to the
+// report, if r is not nil.
+func (r *Report) record(spec fieldSpec, val reflect.Value, source ValueSource) {
+	if r == nil {
+		return
+	}
+	strVal, _ := formatValue(val, spec.tag)
+	var hash string
+	if source != SourceUnset && !spec.tag.Secret {
+		sum := sha256.Sum256([]byte(strVal))
+		hash = hex.EncodeToString(sum[:])
+	}
+	if spec.tag.Secret {
+		strVal = redacted
+	}
+	r.Fields = append(r.Fields, FieldReport{
+		FieldInfo: FieldInfo{
+			Path: spec.path,
+			Name: spec.name,
+			Type: spec.field.Type,
+			Tag:  spec.tag,
+		},
+		Source: source,
+		Value:  strVal,
+		Hash:   hash,
+	})
+}
+
+// secretError replaces an error about the value of a secret field of type
+// t. The messages of parsers quote parts of the values they reject (like
+// the port of a URL, or the keys of a JSON document), so the error only
+// names the type of the value, and doesn't wrap the original one.
+func secretError(t reflect.Type, td TagData) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	kind := schemaType(t, td)
+	if kind == "string" && t.Kind() != reflect.String {
+		kind = t.String() // e.g. netip.Addr
+	}
+	return fmt.Errorf("invalid %s", kind)
+}
@@ -0,0 +1,78 @@
+package parsenv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	var myConfig struct {
+		Host      string `cfg:"required"`
+		Port      int    `cfg:"default=5432"`
+		Debug     bool
+		SentryDSN string `cfg:"recommended"`
+		LogLevel  string `cfg:"recommended;default=info"`
+	}
+	t.Setenv("HOST", "db.internal")
+
+	var report Report
+	if err := Load(&myConfig, WithReport(&report)); err != nil {
+		t.Fatal(err)
+	}
+	var sources []ValueSource
+	for _, f := range report.Fields {
+		sources = append(sources, f.Source)
+	}
+	if expected := []ValueSource{SourceEnv, SourceDefault, SourceUnset, SourceUnset, SourceDefault}; !reflect.DeepEqual(sources, expected) {
+		t.Errorf("expected %v, got: %v", expected, sources)
+	}
+	expected := "5 variables: 1 set, 2 using defaults, 2 optional unset\nmissing recommended variables: SENTRY_DSN"
+	if summary := report.Summary(); summary != expected {
+		t.Errorf("expected %q, got: %q", expected, summary)
+	}
+
+	t.Setenv("SENTRY_DSN", "https://sentry.example.com/1")
+	if err := Load(&myConfig, WithReport(&report)); err != nil {
+		t.Fatal(err)
+	}
+	expected = "5 variables: 2 set, 2 using defaults, 1 optional unset"
+	if summary := report.Summary(); summary != expected {
+		t.Errorf("expected %q, got: %q", expected, summary)
+	}
+}
+
+func TestReportFingerprint(t *testing.T) {
+	var myConfig struct {
+		Host     string
+		Port     int    `cfg:"default=5432"`
+		Password string `cfg:"secret"`
+	}
+	t.Setenv("HOST", "db.internal")
+	t.Setenv("PASSWORD", "hunter2")
+
+	fingerprint := func() string {
+		var report Report
+		if err := Load(&myConfig, WithReport(&report)); err != nil {
+			t.Fatal(err)
+		}
+		return report.Fingerprint()
+	}
+	first := fingerprint()
+	if second := fingerprint(); first != second {
+		t.Errorf("expected a stable fingerprint, got: %s and %s", first, second)
+	}
+	t.Setenv("PASSWORD", "correct horse battery staple")
+	if rotated := fingerprint(); first != rotated {
+		t.Errorf("expected secrets not to affect the fingerprint, got: %s and %s", first, rotated)
+	}
+	t.Setenv("PORT", "6543")
+	if changed := fingerprint(); first == changed {
+		t.Error("expected a different fingerprint after changing PORT")
+	}
+
+	var report Report
+	Load(&myConfig, WithReport(&report))
+	if report.Fields[2].Value != "[REDACTED]" {
+		t.Errorf("expected the password to be redacted, got: %s", report.Fields[2].Value)
+	}
+}
@@ -0,0 +1,80 @@
+package parsenv
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// findSpec returns the field whose variable is called name.
+func findSpec(specs []fieldSpec, name string) (fieldSpec, bool) {
+	i := slices.IndexFunc(specs, func(spec fieldSpec) bool { return spec.name == name })
+	if i < 0 {
+		return fieldSpec{}, false
+	}
+	return specs[i], true
+}
+
+// checkConditions checks the conditions of fields tagged `requiredIf` that
+// refer to other fields of the struct, whose values must be valid for the
+// type of that field.
+func checkConditions(specs []fieldSpec) (errs []error) {
+	for _, spec := range specs {
+		if spec.tag.RequiredIf == "" {
+			continue
+		}
+		if spec.tag.RequiredIf == spec.name {
+			errs = append(errs, &TagError{Field: spec.path, Tag: string(spec.field.Tag), Err: fmt.Errorf("requiredIf must refer to another variable")})
+			continue
+		}
+		other, ok := findSpec(specs, spec.tag.RequiredIf)
+		if !ok || spec.tag.RequiredIfValue == "" {
+			continue
+		}
+		if _, err := parseValue(other.field.Type, spec.tag.RequiredIfValue, other.tag); err != nil {
+			errs = append(errs, &TagError{Field: spec.path, Tag: string(spec.field.Tag), Err: fmt.Errorf("invalid value in requiredIf: %w", err)})
+		}
+	}
+	return errs
+}
+
+// conditionHolds reports whether the field tagged `requiredIf` is required.
+// If the condition refers to another field of the struct, its loaded value
+// (which may be its default) is compared to the value of the condition,
+// parsed like that field, so that e.g. requiredIf=TLS_ENABLED=true also
+// holds for TLS_ENABLED=yes. Otherwise, the variable is looked up and
+// compared as is. Without a value, the condition holds if the field has a
+// value, or the variable is set to a non-empty value, respectively.
+func conditionHolds(cfgVal reflect.Value, specs []fieldSpec, loaded map[string]bool, td TagData, opts Options) (bool, error) {
+	other, ok := findSpec(specs, td.RequiredIf)
+	if !ok {
+		val, _, err := lookupVariable(td.RequiredIf, opts)
+		if err != nil || td.RequiredIfValue == "" {
+			return val != "", err
+		}
+		return val == td.RequiredIfValue, nil
+	}
+	if !loaded[other.name] || td.RequiredIfValue == "" {
+		return loaded[other.name], nil
+	}
+	want, err := parseValue(other.field.Type, td.RequiredIfValue, other.tag)
+	if err != nil {
+		return false, err // reported by checkConditions
+	}
+	wantVal := reflect.New(other.field.Type).Elem()
+	setField(wantVal, want)
+	got, err := formatValue(cfgVal.FieldByIndex(other.index), other.tag)
+	if err != nil {
+		return false, err
+	}
+	wantStr, err := formatValue(wantVal, other.tag)
+	return got == wantStr, err
+}
+
+// requiredIfCondition formats the condition of a field tagged `requiredIf`.
+func requiredIfCondition(td TagData) string {
+	if td.RequiredIfValue == "" {
+		return td.RequiredIf
+	}
+	return td.RequiredIf + "=" + td.RequiredIfValue
+}
@@ -0,0 +1,195 @@
+package parsenv
+
+import (
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// rolloutSuffix and stableSuffix are the default suffixes of the variables
+// holding the percentage of instances a change is rolled out to, and the
+// value the other instances keep using.
+const (
+	rolloutSuffix = "_ROLLOUT"
+	stableSuffix  = "_STABLE"
+)
+
+// A Rollout is a Source that rolls out changes of the variables of another
+// Source gradually across a fleet. A change is rolled out by setting the
+// variable to its new value, the variable with StableSuffix appended to the
+// previous value, and the variable with RolloutSuffix appended to the
+// percentage of instances that use the new value:
+//
+//	FEATURE_X=v2
+//	FEATURE_X_STABLE=v1
+//	FEATURE_X_ROLLOUT=25
+//
+// All other instances use the stable value, or see the variable as unset if
+// there is no stable value. Once the rollout is finished, the two other
+// variables are removed. Without a percentage, the value is used everywhere.
+//
+//	src := &parsenv.ConfigServiceSource{Client: client, Prefix: "billing/"}
+//	ro := &parsenv.Rollout{Source: src}
+//	go src.Watch(ctx, func() {
+//		var newCfg Config
+//		if err := parsenv.Load(&newCfg, parsenv.WithLookuper(ro)); err == nil {
+//			// swap in newCfg
+//		}
+//		for _, st := range ro.Status() {
+//			slog.Info("rollout", "var", st.Name, "percent", st.Percent, "applied", st.Applied)
+//		}
+//	})
+//
+// Whether an instance gets the new value is decided by a hash of the
+// instance name and the name of the variable, so the same instances get it
+// on every lookup, raising the percentage only adds instances, and lowering
+// it to 0 rolls the change back. Different variables are rolled out to
+// different subsets of the fleet. Since all of this state is kept in the
+// Source, instances that restart or are added during a rollout get the same
+// value as the instances that were running all along.
+//
+// A Rollout is safe for concurrent use.
+type Rollout struct {
+	Source Lookuper
+
+	// Instance identifies this instance. Defaults to the hostname.
+	Instance string
+
+	// RolloutSuffix is appended to the name of a variable to get the name
+	// of the variable holding its rollout percentage. Defaults to
+	// "_ROLLOUT".
+	RolloutSuffix string
+
+	// StableSuffix is appended to the name of a variable to get the name
+	// of the variable holding its stable value. Defaults to "_STABLE".
+	StableSuffix string
+
+	mu     sync.Mutex
+	status map[string]RolloutStatus // by name, of the pending rollouts
+	bucket map[string]int           // by name, cached hashes
+}
+
+// A RolloutStatus describes a change of a variable that is being rolled out.
+type RolloutStatus struct {
+	Name    string // name of the variable
+	Percent int    // percentage of instances the change is rolled out to
+	Applied bool   // whether this instance uses the new value
+}
+
+// Lookup is like LookupErr, but treats errors as absent values.
+func (r *Rollout) Lookup(name string) (string, bool) {
+	val, ok, _ := r.LookupErr(name)
+	return val, ok
+}
+
+// LookupErr returns the value of the variable name in Source, or its stable
+// value, if a change is being rolled out that doesn't include this instance
+// yet.
+func (r *Rollout) LookupErr(name string) (string, bool, error) {
+	return r.lookupIn(r.Source, name)
+}
+
+// Snapshot returns a Rollout of a snapshot of Source, which reports its
+// status to r.
+func (r *Rollout) Snapshot() (Lookuper, error) {
+	src, err := snapshot(r.Source)
+	if err != nil {
+		return nil, err
+	}
+	return rolloutSnapshot{r, src}, nil
+}
+
+// lookupIn is LookupErr with the variables looked up in src.
+func (r *Rollout) lookupIn(src Lookuper, name string) (string, bool, error) {
+	percent, rolling, err := r.percent(src, name)
+	if err != nil {
+		return "", false, err
+	}
+	r.mu.Lock()
+	applied := !rolling || r.bucketOf(name) < percent
+	if rolling {
+		if r.status == nil {
+			r.status = map[string]RolloutStatus{}
+		}
+		r.status[name] = RolloutStatus{Name: name, Percent: percent, Applied: applied}
+	} else {
+		delete(r.status, name)
+	}
+	r.mu.Unlock()
+	if applied {
+		return lookupErr(src, name)
+	}
+	return lookupErr(src, name+cmp.Or(r.StableSuffix, stableSuffix))
+}
+
+// rolloutSnapshot is a Source rolling out the changes of a snapshot of the
+// Source of a Rollout.
+type rolloutSnapshot struct {
+	r   *Rollout
+	src Lookuper
+}
+
+func (s rolloutSnapshot) Lookup(name string) (string, bool) {
+	val, ok, _ := s.LookupErr(name)
+	return val, ok
+}
+
+func (s rolloutSnapshot) LookupErr(name string) (string, bool, error) {
+	return s.r.lookupIn(s.src, name)
+}
+
+// percent returns the rollout percentage of the variable name in src.
+// rolling is false if no percentage is set, or it is 100.
+func (r *Rollout) percent(src Lookuper, name string) (percent int, rolling bool, err error) {
+	suffix := cmp.Or(r.RolloutSuffix, rolloutSuffix)
+	val, ok, err := lookupErr(src, name+suffix)
+	if err != nil || !ok {
+		return 100, false, err
+	}
+	percent, err = strconv.Atoi(val)
+	if err != nil || percent < 0 || percent > 100 {
+		return 0, false, fmt.Errorf("invalid rollout percentage %s=%q: must be an integer from 0 to 100", name+suffix, val)
+	}
+	return percent, percent < 100, nil
+}
+
+// bucketOf returns the bucket (0 to 99) of this instance for the variable
+// name. The change of the variable is applied if the bucket is less than
+// the rollout percentage.
+func (r *Rollout) bucketOf(name string) int {
+	if b, ok := r.bucket[name]; ok {
+		return b
+	}
+	instance := r.Instance
+	if instance == "" {
+		instance, _ = os.Hostname()
+	}
+	h := fnv.New32a()
+	h.Write([]byte(instance))
+	h.Write([]byte{0})
+	h.Write([]byte(name))
+	b := int(h.Sum32() % 100)
+	if r.bucket == nil {
+		r.bucket = map[string]int{}
+	}
+	r.bucket[name] = b
+	return b
+}
+
+// Status reports the changes that are currently being rolled out, as of
+// the last lookup of each variable, sorted by the name of the variable. The
+// values themselves are not included, since they may be secret.
+func (r *Rollout) Status() []RolloutStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := slices.Collect(maps.Values(r.status))
+	slices.SortFunc(status, func(a, b RolloutStatus) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return status
+}
@@ -0,0 +1,141 @@
+package parsenv
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRollout(t *testing.T) {
+	src := MapLookuper{"LIMIT": "10"}
+	fleet := make([]*Rollout, 100)
+	for i := range fleet {
+		fleet[i] = &Rollout{Source: src, Instance: fmt.Sprintf("web-%d", i)}
+	}
+	lookupAll := func() (updated map[int]bool) {
+		updated = map[int]bool{}
+		for i, ro := range fleet {
+			val, ok := ro.Lookup("LIMIT")
+			if !ok {
+				t.Fatalf("expected LIMIT to be set on instance %d", i)
+			}
+			if val == "20" {
+				updated[i] = true
+			}
+		}
+		return updated
+	}
+	if updated := lookupAll(); len(updated) != 0 {
+		t.Fatalf("expected the stable value everywhere, got: %v", updated)
+	}
+
+	src["LIMIT"] = "20"
+	src["LIMIT_STABLE"] = "10"
+	src["LIMIT_ROLLOUT"] = "25"
+	quarter := lookupAll()
+	if len(quarter) < 10 || len(quarter) > 40 {
+		t.Errorf("expected about a quarter of the instances to be updated, got: %d", len(quarter))
+	}
+	for i := range quarter {
+		st := fleet[i].Status()
+		if len(st) != 1 || st[0] != (RolloutStatus{Name: "LIMIT", Percent: 25, Applied: true}) {
+			t.Errorf("expected the status to report the applied rollout, got: %+v", st)
+		}
+	}
+
+	src["LIMIT_ROLLOUT"] = "50"
+	half := lookupAll()
+	for i := range quarter {
+		if !half[i] {
+			t.Errorf("expected instance %d to keep the new value when raising the percentage", i)
+		}
+	}
+
+	src["LIMIT_ROLLOUT"] = "0"
+	if updated := lookupAll(); len(updated) != 0 {
+		t.Errorf("expected the change to be rolled back, got: %v", updated)
+	}
+
+	delete(src, "LIMIT_ROLLOUT")
+	delete(src, "LIMIT_STABLE")
+	if updated := lookupAll(); len(updated) != len(fleet) {
+		t.Errorf("expected the new value everywhere, got: %d", len(updated))
+	}
+	if st := fleet[0].Status(); len(st) != 0 {
+		t.Errorf("expected no pending rollouts, got: %+v", st)
+	}
+}
+
+func TestRolloutRestart(t *testing.T) {
+	src := MapLookuper{"LIMIT": "20", "LIMIT_STABLE": "10", "LIMIT_ROLLOUT": "25"}
+	var included, excluded string
+	for i := 0; included == "" || excluded == ""; i++ {
+		instance := fmt.Sprintf("web-%d", i)
+		if val, _ := (&Rollout{Source: src, Instance: instance}).Lookup("LIMIT"); val == "20" {
+			included = instance
+		} else {
+			excluded = instance
+		}
+	}
+
+	// an instance that (re)starts during the rollout sees the new value for
+	// the first time, but must still get the stable value
+	restarted := &Rollout{Source: src, Instance: excluded}
+	if val, _ := restarted.Lookup("LIMIT"); val != "10" {
+		t.Errorf("expected the restarted instance to use the stable value, got: %s", val)
+	}
+	src["LIMIT_ROLLOUT"] = "0"
+	if val, _ := (&Rollout{Source: src, Instance: included}).Lookup("LIMIT"); val != "10" {
+		t.Errorf("expected lowering the percentage to 0 to roll back restarted instances, got: %s", val)
+	}
+
+	// rolling out a new variable: excluded instances see it as unset
+	src = MapLookuper{"FEATURE": "on", "FEATURE_ROLLOUT": "25"}
+	if _, ok := (&Rollout{Source: src, Instance: excluded}).Lookup("FEATURE"); ok {
+		t.Error("expected FEATURE to be unset on excluded instances")
+	}
+}
+
+func TestRolloutInvalidPercentage(t *testing.T) {
+	src := MapLookuper{"LIMIT": "10"}
+	ro := &Rollout{Source: src, Instance: "web-0"}
+	var myConfig struct {
+		Limit int
+	}
+	if err := Load(&myConfig, WithLookuper(ro)); err != nil {
+		t.Fatal(err)
+	}
+	src["LIMIT"] = "20"
+	src["LIMIT_STABLE"] = "10"
+	src["LIMIT_ROLLOUT"] = "half"
+	if err := Load(&myConfig, WithLookuper(ro)); err == nil {
+		t.Error("expected an error for an invalid percentage, got nil")
+	}
+}
+
+func TestRolloutConcurrent(t *testing.T) {
+	client := &fakeConfigService{
+		values:  map[string]string{"billing/LIMIT": "20", "billing/LIMIT_STABLE": "10"},
+		updates: make(chan map[string]string),
+	}
+	src := &ConfigServiceSource{Client: client, Prefix: "billing/"}
+	ro := &Rollout{Source: src, Instance: "web-0"}
+	done := make(chan error)
+	go func() { done <- src.Watch(context.Background(), nil) }()
+	var percent atomic.Int32
+	concurrently(t, 50, func() error {
+		client.updates <- map[string]string{"billing/LIMIT_ROLLOUT": fmt.Sprint(percent.Add(1) % 100)}
+		var myConfig struct{ Limit int }
+		if err := Load(&myConfig, WithLookuper(ro)); err != nil {
+			return err
+		}
+		if myConfig.Limit != 10 && myConfig.Limit != 20 {
+			return fmt.Errorf("unexpected limit: %d", myConfig.Limit)
+		}
+		ro.Status()
+		return nil
+	})
+	close(client.updates)
+	<-done
+}
@@ -0,0 +1,104 @@
+package parsenv
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A ModTimeLookuper is a Lookuper that knows when the values of variables
+// were last changed, like a secret manager keeping versions of secrets. It
+// lets Load warn about values of fields tagged `rotate` that are overdue
+// for rotation, see StaleWarning.
+type ModTimeLookuper interface {
+	Lookuper
+
+	// ModTime returns when the value of the variable name was last changed.
+	// The boolean is false if that is unknown.
+	ModTime(name string) (time.Time, bool)
+}
+
+// ModTime returns the modification time reported by the first of the
+// lookupers that has the variable name, if it is a ModTimeLookuper.
+func (m multiLookuper) ModTime(name string) (time.Time, bool) {
+	for _, l := range m {
+		if _, ok, err := lookupErr(l, name); err != nil || !ok {
+			continue
+		}
+		if ml, ok := l.(ModTimeLookuper); ok {
+			return ml.ModTime(name)
+		}
+		return time.Time{}, false
+	}
+	return time.Time{}, false
+}
+
+// parsePeriod parses the period of the `rotate` property, a duration as
+// understood by time.ParseDuration, or a number of days like 30d.
+func parsePeriod(s string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid period %q: must be a positive duration like 720h or 30d", s)
+	}
+	return d, nil
+}
+
+// formatPeriod formats d in days, if it is at least one day long.
+func formatPeriod(d time.Duration) string {
+	if d >= 24*time.Hour {
+		return strconv.Itoa(int(d/(24*time.Hour))) + "d"
+	}
+	return d.Round(time.Second).String()
+}
+
+// valueModTime returns when the value of the field was last changed: the
+// modification time of the file it was read from (see the `file` property
+// and Options.FileVariables), or the time reported by the Lookuper, if it is
+// a ModTimeLookuper.
+func valueModTime(spec fieldSpec, opts Options) (time.Time, bool) {
+	opts = opts.forField(spec)
+	var path string
+	if spec.tag.File != "" {
+		path = spec.tag.File
+		if p, ok := opts.lookup(spec.name); ok && p != "" {
+			path = p
+		}
+	} else if opts.FileVariables {
+		if val, ok := opts.lookup(spec.name); !ok || val == "" {
+			path, _ = opts.lookup(spec.name + fileSuffix)
+		}
+	}
+	if path != "" {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return fi.ModTime(), true
+	}
+	if ml, ok := opts.Lookuper.(ModTimeLookuper); ok {
+		return ml.ModTime(spec.name)
+	}
+	return time.Time{}, false
+}
+
+// checkRotation warns about the value of a field tagged `rotate` that is
+// older than the rotation period, as of Options.Now.
+func checkRotation(spec fieldSpec, opts Options) {
+	modTime, ok := valueModTime(spec, opts)
+	if !ok {
+		return
+	}
+	if age := opts.now().Sub(modTime); age > spec.tag.Rotate {
+		opts.warn(&StaleWarning{Field: spec.path, Name: spec.name, Owner: spec.tag.Owner, ModTime: modTime, Age: age, Rotate: spec.tag.Rotate})
+	}
+}
@@ -0,0 +1,105 @@
+package parsenv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type versionedLookuper struct {
+	MapLookuper
+	modTimes map[string]time.Time
+}
+
+func (v versionedLookuper) ModTime(name string) (time.Time, bool) {
+	t, ok := v.modTimes[name]
+	return t, ok
+}
+
+func TestLoadRotate(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-45 * 24 * time.Hour)
+	if err := os.Chtimes(tokenFile, old, old); err != nil {
+		t.Fatal(err)
+	}
+	var myConfig struct {
+		Token  string `cfg:"rotate=30d;owner=team security"`
+		Fresh  string `cfg:"rotate=30d"`
+		Forget string `cfg:"rotate=720h"`
+	}
+	l := versionedLookuper{
+		MapLookuper: MapLookuper{"TOKEN_FILE": tokenFile, "FRESH": "new", "FORGET": "unknown"},
+		modTimes:    map[string]time.Time{"FRESH": time.Now().Add(-time.Hour)},
+	}
+	var warnings []error
+
+	if err := Load(&myConfig, WithLookuper(MultiLookuper(l)), WithFileVariables(), WithWarn(func(err error) { warnings = append(warnings, err) })); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected one warning, got: %v", warnings)
+	}
+	var stale *StaleWarning
+	if !errors.As(warnings[0], &stale) {
+		t.Fatalf("expected a *StaleWarning, got: %v", warnings[0])
+	}
+	if stale.Name != "TOKEN" || stale.Rotate != 30*24*time.Hour || !stale.ModTime.Equal(old) {
+		t.Errorf("expected a warning about TOKEN, got: %+v", stale)
+	}
+	if expected := "value of TOKEN for field Token (owned by team security) was last changed 45d ago, but should be rotated every 30d"; stale.Error() != expected {
+		t.Errorf("expected %q, got: %q", expected, stale.Error())
+	}
+
+	l.modTimes["FRESH"] = time.Now().Add(-31 * 24 * time.Hour)
+	warnings = nil
+	if err := Load(&myConfig, WithLookuper(l), WithWarn(func(err error) { warnings = append(warnings, err) })); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || !errors.As(warnings[0], &stale) || stale.Name != "FRESH" {
+		t.Errorf("expected a warning about FRESH, got: %v", warnings)
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	for _, s := range []string{"0d", "-1h", "30", "month"} {
+		if _, err := parsePeriod(s); err == nil {
+			t.Errorf("expected an error for %q, got nil", s)
+		}
+	}
+	if d, err := parsePeriod("90d"); err != nil || d != 90*24*time.Hour {
+		t.Errorf("expected 2160h, got: %v, %v", d, err)
+	}
+}
+
+func TestLoadRotateNow(t *testing.T) {
+	modTime := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var myConfig struct {
+		Token string `cfg:"rotate=30d"`
+	}
+	l := versionedLookuper{
+		MapLookuper: MapLookuper{"TOKEN": "secret"},
+		modTimes:    map[string]time.Time{"TOKEN": modTime},
+	}
+	var warnings []error
+	warn := WithWarn(func(err error) { warnings = append(warnings, err) })
+
+	if err := Load(&myConfig, WithLookuper(l), warn, WithNow(func() time.Time { return modTime.AddDate(0, 0, 29) })); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings, got: %v", warnings)
+	}
+	if err := Load(&myConfig, WithLookuper(l), warn, WithNow(func() time.Time { return modTime.AddDate(0, 0, 40) })); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), "was last changed 40d ago") {
+		t.Errorf("expected a warning about the pinned age, got: %v", warnings)
+	}
+}
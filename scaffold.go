@@ -0,0 +1,114 @@
+package parsenv
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"text/template"
+)
+
+// WriteScaffold writes the source of a main package to w that loads the
+// config struct type of cfg, to get started with parsenv quickly. The
+// program
+//
+//   - prints the variables it reads with `prog --help-env`,
+//   - loads the config, logging warnings and failures with log/slog,
+//   - with `prog check-config`, only loads the config and prints a summary,
+//     e.g. to validate an environment before deploying it,
+//   - and otherwise passes the config to a run function, which is left to
+//     be written.
+//
+// cfg must be a (pointer to a) named struct type. WriteScaffold is meant to
+// be called from a throwaway program or test:
+//
+//	parsenv.WriteScaffold(os.Stdout, &config.Config{})
+//
+// The parsenv command does that for a package given on the command line:
+//
+//	go run github.com/cvanloo/parsenv/cmd/parsenv scaffold -o cmd/app/main.go ./internal/config
+func WriteScaffold(w io.Writer, cfg any) error {
+	t := reflect.TypeOf(cfg)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || t.Name() == "" {
+		return fmt.Errorf("cfg must be a named struct type, got: %v", t)
+	}
+	if _, err := Describe(cfg); err != nil {
+		return err
+	}
+	data := struct{ Import, Type string }{Type: t.Name()}
+	if pkg := t.PkgPath(); pkg != "main" {
+		// The package name needn't match the last element of its path
+		// (e.g. example.com/config/v2 or example.com/go-config), so it is
+		// imported under a fixed name.
+		data.Import = pkg
+		data.Type = "config." + t.Name()
+	}
+	var src bytes.Buffer
+	if err := scaffoldTemplate.Execute(&src, data); err != nil {
+		return err
+	}
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+var scaffoldTemplate = template.Must(template.New("main.go").Parse(`package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/cvanloo/parsenv"
+{{- if .Import}}
+	config {{printf "%q" .Import}}
+{{- end}}
+)
+
+func main() {
+	var cfg {{.Type}}
+	if len(os.Args) > 1 && os.Args[1] == "--help-env" {
+		usage, err := parsenv.Usage(&cfg)
+		if err != nil {
+			slog.Error("describing configuration", "err", err)
+			os.Exit(1)
+		}
+		fmt.Print(usage)
+		return
+	}
+
+	var report parsenv.Report
+	err := parsenv.Load(&cfg,
+		parsenv.WithReport(&report),
+		parsenv.WithWarn(func(err error) {
+			slog.Warn("configuration", "err", err)
+		}),
+	)
+	if err != nil {
+		slog.Error("invalid configuration", "err", err)
+		os.Exit(1)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "check-config" {
+		fmt.Println(report.Summary())
+		return
+	}
+	slog.Info("configuration loaded", "summary", report.Summary(), "fingerprint", report.Fingerprint())
+
+	if err := run(cfg); err != nil {
+		slog.Error("exiting", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run(cfg {{.Type}}) error {
+	// TODO: start the application
+	return nil
+}
+`))
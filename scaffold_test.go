@@ -0,0 +1,41 @@
+package parsenv
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+type scaffoldConfig struct {
+	Port int    `cfg:"default=8080"`
+	DSN  string `cfg:"required"`
+}
+
+func TestWriteScaffold(t *testing.T) {
+	var out strings.Builder
+	if err := WriteScaffold(&out, &scaffoldConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	src := out.String()
+	if _, err := parser.ParseFile(token.NewFileSet(), "main.go", src, 0); err != nil {
+		t.Fatalf("expected valid Go source, got: %v\n%s", err, src)
+	}
+	for _, expected := range []string{
+		`"github.com/cvanloo/parsenv"`,
+		`config "github.com/cvanloo/parsenv"`,
+		"var cfg config.scaffoldConfig",
+		`os.Args[1] == "--help-env"`,
+		`os.Args[1] == "check-config"`,
+		"parsenv.WithReport(&report)",
+		"slog.Warn(",
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("expected scaffold to contain %q, got:\n%s", expected, src)
+		}
+	}
+
+	if err := WriteScaffold(&out, &struct{ Port int }{}); err == nil {
+		t.Error("expected an error for an anonymous struct, got nil")
+	}
+}
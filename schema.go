@@ -0,0 +1,237 @@
+package parsenv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// fieldSpec describes how a single field is loaded.
+type fieldSpec struct {
+	field reflect.StructField
+	index []int  // index sequence of the field, relative to the root struct
+	path  string // path of the field, e.g. Database.Host
+	name  string // name of the environment variable
+	tag   TagData
+}
+
+// compileStruct determines which fields of the struct type t are loaded and
+// how. Problems with the struct definition itself, such as invalid tags, are
+// returned as errors.
+func compileStruct(t reflect.Type, opts Options) ([]fieldSpec, []error) {
+	specs, errs := compileFields(t, nil, "", "", opts)
+	return specs, append(errs, checkConditions(specs)...)
+}
+
+// compileFields compiles the fields of the struct type t. Names of
+// environment variables are prefixed with prefix, unless a custom name is
+// specified.
+// Struct-typed fields are recursed into, using the field's name (or the
+// `prefix` property of its tag) as the prefix for the nested fields:
+//
+//	var myConfig struct {
+//		Database struct {
+//			Host string // DATABASE_HOST
+//			Port int    // DATABASE_PORT
+//		}
+//	}
+//
+// Fields promoted from embedded structs are compiled like the struct's own
+// fields, see walkEmbedded.
+func compileFields(t reflect.Type, index []int, path, prefix string, opts Options) (specs []fieldSpec, errs []error) {
+	for _, field := range reflect.VisibleFields(t) {
+		embedPath, embedPrefix, viaUnexported, ok := walkEmbedded(t, field.Index, opts)
+		if !ok {
+			continue
+		}
+		fieldPath := path + embedPath + field.Name
+		fieldPrefix := prefix + embedPrefix
+		td, terr := parseTag(field.Tag.Get("cfg"))
+		if terr == nil {
+			terr = lintTag(td)
+		}
+		if terr != nil {
+			errs = append(errs, &TagError{Field: fieldPath, Tag: string(field.Tag), Err: terr})
+			continue
+		}
+		if td.Ignored {
+			continue
+		}
+		if isEmbeddedStruct(field) && !td.JSON {
+			continue // its fields are visited as promoted fields
+		}
+		if t, ok := lazyElem(field.Type); ok {
+			field.Type = t // Lazy[T] is loaded like T
+		}
+		if opts.Profile != "" && slices.Contains(td.RequiredIn, opts.Profile) {
+			td.Required = true
+		}
+		if opts.StrictValues {
+			td.Strict = true
+		}
+		if opts.ExpandValues && !td.NoExpand {
+			td.Expand = true
+		}
+		if !opts.BoolWords.isZero() {
+			td.bools = &opts.BoolWords
+		}
+		if !td.JSON && isUnloadable(field.Type) {
+			if opts.Strict {
+				errs = append(errs, fmt.Errorf("field %s of type %s cannot be loaded from the environment", fieldPath, field.Type))
+			}
+			continue
+		}
+		if (!field.IsExported() || viaUnexported) && (opts.NoUnsafe || !unsafeAllowed) {
+			errs = append(errs, &TagError{Field: fieldPath, Tag: string(field.Tag), Err: errors.New("field is unexported, but loading unexported fields is disabled (tag it with - to skip it)")})
+			continue
+		}
+		fieldIndex := append(slices.Clone(index), field.Index...)
+		if field.Type.Kind() == reflect.Struct && !isSingleValue(field.Type) && !td.JSON {
+			nestedPrefix := fieldPrefix + opts.NameCase.NameFor(field.Name) + "_"
+			if td.HasPrefix {
+				nestedPrefix = fieldPrefix + td.Prefix
+			}
+			nestedSpecs, nestedErrs := compileFields(field.Type, fieldIndex, fieldPath+".", nestedPrefix, opts)
+			specs = append(specs, nestedSpecs...)
+			errs = append(errs, nestedErrs...)
+			continue
+		}
+		if td.HasPrefix {
+			errs = append(errs, &TagError{Field: fieldPath, Tag: string(field.Tag), Err: fmt.Errorf("prefix is only valid on struct fields")})
+			continue
+		}
+		if td.Encoding != "" && !isBytes(field.Type) && !(field.Type.Kind() == reflect.Pointer && isBytes(field.Type.Elem())) {
+			errs = append(errs, &TagError{Field: fieldPath, Tag: string(field.Tag), Err: fmt.Errorf("encoding is only valid on []byte fields")})
+			continue
+		}
+		if len(td.Schemes) > 0 && field.Type != urlType && field.Type != reflect.PointerTo(urlType) {
+			errs = append(errs, &TagError{Field: fieldPath, Tag: string(field.Tag), Err: fmt.Errorf("schemes is only valid on url.URL fields")})
+			continue
+		}
+		if len(td.OneOf) > 0 {
+			if err := checkOneOf(field.Type, td); err != nil {
+				errs = append(errs, &TagError{Field: fieldPath, Tag: string(field.Tag), Err: err})
+				continue
+			}
+		}
+		if td.Weighted && !isWeightMap(field.Type) {
+			errs = append(errs, &TagError{Field: fieldPath, Tag: string(field.Tag), Err: fmt.Errorf("weighted is only valid on maps with integer values")})
+			continue
+		}
+		if td.Unit != "" {
+			if _, ok := lookupUnits(td.Unit); !ok {
+				errs = append(errs, &TagError{Field: fieldPath, Tag: string(field.Tag), Err: fmt.Errorf("unknown unit system: %q (register it with RegisterUnits)", td.Unit)})
+				continue
+			}
+			if !isUnitKind(unitTarget(field.Type).Kind()) {
+				errs = append(errs, &TagError{Field: fieldPath, Tag: string(field.Tag), Err: fmt.Errorf("unit is only valid on numeric fields")})
+				continue
+			}
+		}
+		if err := checkTransforms(td); err != nil {
+			errs = append(errs, &TagError{Field: fieldPath, Tag: string(field.Tag), Err: err})
+			continue
+		}
+		if err := checkDefaultFunc(td); err != nil {
+			errs = append(errs, &TagError{Field: fieldPath, Tag: string(field.Tag), Err: err})
+			continue
+		}
+		if err := checkValidators(td); err != nil {
+			errs = append(errs, &TagError{Field: fieldPath, Tag: string(field.Tag), Err: err})
+			continue
+		}
+		if td.Pattern != "" {
+			re, err := compilePattern(t, field.Type, td.Pattern)
+			if err != nil {
+				errs = append(errs, &TagError{Field: fieldPath, Tag: string(field.Tag), Err: err})
+				continue
+			}
+			td.pattern = re
+		}
+		if td.pattern != nil && td.Example == "" && (td.Default == "" || td.Secret) {
+			if _, err := parseValue(field.Type, exampleValue(field.Type, td), td); err != nil {
+				errs = append(errs, &TagError{Field: fieldPath, Tag: string(field.Tag), Err: fmt.Errorf("no example matching the pattern can be synthesized, give one with example=<value>")})
+				continue
+			}
+		}
+		if td.Min != "" || td.Max != "" {
+			if err := checkBounds(field.Type, td); err != nil {
+				errs = append(errs, &TagError{Field: fieldPath, Tag: string(field.Tag), Err: err})
+				continue
+			}
+		}
+		if td.Default != "" && !(td.Expand && strings.ContainsAny(td.Default, "$%")) {
+			// catch typos in defaults even if the variable is always set
+			def, err := applyTransforms(td.Default, td)
+			if now, ok, rerr := relativeTime(field.Type, td.Default, opts.now()); ok {
+				def, err = now.Format(timeLayout(td)), rerr
+			}
+			if err == nil {
+				_, err = parseValue(field.Type, def, td)
+			}
+			if err != nil && td.Secret {
+				err = secretError(field.Type, td)
+			}
+			if err != nil {
+				errs = append(errs, &TagError{Field: fieldPath, Tag: string(field.Tag), Err: fmt.Errorf("invalid default value: %w", err)})
+				continue
+			}
+		}
+		name := td.Name
+		if name == "" {
+			name = fieldPrefix + opts.NameCase.NameFor(field.Name)
+		}
+		specs = append(specs, fieldSpec{
+			field: field,
+			index: fieldIndex,
+			path:  fieldPath,
+			name:  name,
+			tag:   td,
+		})
+	}
+	return specs, errs
+}
+
+// walkEmbedded walks the embedded structs through which the field with the
+// given index sequence is promoted into t. It returns the path and prefix
+// contributed by them, and whether any of them is unexported. The path
+// includes the names of the embedded types (LogConfig.Level), the prefix
+// only does if Options.PrefixEmbedded is set, or the embedded field has a
+// `prefix` property.
+//
+// Fields promoted through embedded structs that are ignored, loaded as JSON,
+// have an invalid tag (reported for the embedded field itself), or are
+// embedded by pointer, are skipped (ok is false).
+func walkEmbedded(t reflect.Type, index []int, opts Options) (path, prefix string, unexported, ok bool) {
+	for _, i := range index[:len(index)-1] {
+		embedded := t.Field(i)
+		td, err := parseTag(embedded.Tag.Get("cfg"))
+		if err != nil || td.Ignored || td.JSON || embedded.Type.Kind() != reflect.Struct || isSingleValue(embedded.Type) {
+			return "", "", false, false
+		}
+		path += embedded.Name + "."
+		if td.HasPrefix {
+			prefix += td.Prefix
+		} else if opts.PrefixEmbedded {
+			prefix += opts.NameCase.NameFor(embedded.Name) + "_"
+		}
+		unexported = unexported || !embedded.IsExported()
+		t = embedded.Type
+	}
+	return path, prefix, unexported, true
+}
+
+// isEmbeddedStruct reports whether field is an embedded struct (or pointer to
+// a struct) whose fields are promoted.
+func isEmbeddedStruct(field reflect.StructField) bool {
+	if !field.Anonymous {
+		return false
+	}
+	t := field.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isSingleValue(t)
+}
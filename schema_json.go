@@ -0,0 +1,196 @@
+package parsenv
+
+import (
+	"cmp"
+	"encoding/json"
+	"reflect"
+)
+
+// SchemaVersion is the version of the format of Schema. It is incremented
+// whenever the format changes incompatibly, i.e. a property is removed or
+// changes its meaning. New properties may be added within the same version.
+const SchemaVersion = 1
+
+// A Schema describes the variables a config struct reads in a documented,
+// language-independent form, so that tooling written in other languages
+// (deployment validators, forms for editing the config, ...) can check
+// against the exact same contract as Load:
+//
+//	{
+//	  "version": 1,
+//	  "variables": [
+//	    {"field": "Port", "envVar": "PORT", "type": "integer", "goType": "int", "default": "8080", "example": "8080"},
+//	    {"field": "Hosts", "envVar": "HOSTS", "type": "list", "elemType": "string", "goType": "[]string", "required": true, "example": "example,example", "separator": ","}
+//	  ]
+//	}
+type Schema struct {
+	Version   int              `json:"version"`   // SchemaVersion
+	Variables []SchemaVariable `json:"variables"` // in the order Load visits them
+}
+
+// A SchemaVariable describes a single variable of a Schema. Properties that
+// don't apply to the variable are omitted.
+type SchemaVariable struct {
+	Field   string   `json:"field"`             // path of the struct field, e.g. Database.Host
+	EnvVar  string   `json:"envVar"`            // name of the environment variable
+	Aliases []string `json:"aliases,omitempty"` // alternative names, looked up in order if EnvVar is unset
+	Usage   string   `json:"usage,omitempty"`   // description of the variable
+
+	// Type is one of string, integer, number, boolean, time, url, bytes,
+	// json (a JSON document), list, or map. Types with a custom text format
+	// (like netip.Addr) are strings, see GoType and Example for details.
+	Type     string `json:"type"`
+	ElemType string `json:"elemType,omitempty"` // type of the elements of lists, or the values of maps
+	KeyType  string `json:"keyType,omitempty"`  // type of the keys of maps
+	GoType   string `json:"goType"`             // type of the struct field
+
+	Required    bool     `json:"required,omitempty"`
+	RequiredIn  []string `json:"requiredIn,omitempty"`  // profiles in which the variable is required
+	RequiredIf  string   `json:"requiredIf,omitempty"`  // condition under which the variable is required, NAME (set) or NAME=value
+	NotEmpty    bool     `json:"notEmpty,omitempty"`    // whether an empty value is rejected
+	Unset       bool     `json:"unset,omitempty"`       // whether the variable is removed from the environment after loading
+	Recommended bool     `json:"recommended,omitempty"` // optional, but warned about when missing
+	Deprecated  string   `json:"deprecated,omitempty"`  // the reason the variable is deprecated, or "deprecated" if none was given
+	Default     string   `json:"default,omitempty"`
+	DefaultFunc string   `json:"defaultFunc,omitempty"` // name of the function computing the default when the variable is unset
+	Example     string   `json:"example,omitempty"`
+	Secret      bool     `json:"secret,omitempty"`
+	Owner       string   `json:"owner,omitempty"` // who to ask about the variable
+	OneOf       []string `json:"oneOf,omitempty"` // allowed values (of each element, for lists)
+
+	Separator   string   `json:"separator,omitempty"`   // between the elements of lists and the pairs of maps
+	KVSeparator string   `json:"kvSeparator,omitempty"` // between the keys and values of maps
+	Layout      string   `json:"layout,omitempty"`      // time layout, in the notation of Go's time package
+	Encoding    string   `json:"encoding,omitempty"`    // encoding of bytes
+	Schemes     []string `json:"schemes,omitempty"`     // allowed URL schemes
+	Unit        string   `json:"unit,omitempty"`        // name of the unit system of quantities, e.g. bytes
+	Min         string   `json:"min,omitempty"`         // smallest accepted value, inclusive
+	Max         string   `json:"max,omitempty"`         // largest accepted value, inclusive
+	Pattern     string   `json:"pattern,omitempty"`     // regular expression string values must match
+	Charset     string   `json:"charset,omitempty"`     // characters values may consist of, like [:alnum:]._-
+	Validate    []string `json:"validate,omitempty"`    // names of the validators checking the value
+	Transform   []string `json:"transform,omitempty"`   // names of the transforms applied to the value before parsing
+
+	// Hints for UIs editing the config, see the widget, group, and order
+	// properties of TagData. They have no meaning to parsenv itself.
+	Widget string `json:"widget,omitempty"`
+	Group  string `json:"group,omitempty"`
+	Order  int    `json:"order,omitempty"`
+}
+
+// DescribeSchema returns the Schema of the variables read into cfg, see
+// Describe.
+func DescribeSchema(cfg any, opts ...Option) (*Schema, error) {
+	infos, err := Describe(cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s := &Schema{Version: SchemaVersion, Variables: make([]SchemaVariable, len(infos))}
+	for i, fi := range infos {
+		s.Variables[i] = schemaVariable(fi)
+	}
+	return s, nil
+}
+
+// SchemaJSON renders the Schema of cfg as JSON.
+func SchemaJSON(cfg any, opts ...Option) ([]byte, error) {
+	s, err := DescribeSchema(cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func schemaVariable(fi FieldInfo) SchemaVariable {
+	td := fi.Tag
+	t := fi.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	v := SchemaVariable{
+		Field:       fi.Path,
+		EnvVar:      fi.Name,
+		Aliases:     td.Alias,
+		Usage:       td.Usage,
+		Type:        schemaType(t, td),
+		GoType:      fi.Type.String(),
+		Required:    td.Required,
+		RequiredIn:  td.RequiredIn,
+		RequiredIf:  requiredIfCondition(td),
+		NotEmpty:    td.NotEmpty,
+		Unset:       td.Unset,
+		Recommended: td.Recommended,
+		Default:     td.Default,
+		DefaultFunc: td.DefaultFunc,
+		Example:     fi.Example(),
+		Secret:      td.Secret,
+		Owner:       td.Owner,
+		OneOf:       td.OneOf,
+		Encoding:    td.Encoding,
+		Schemes:     td.Schemes,
+		Unit:        td.Unit,
+		Min:         td.Min,
+		Max:         td.Max,
+		Pattern:     td.Pattern,
+		Charset:     td.Charset,
+		Validate:    td.Validate,
+		Transform:   td.Transform,
+		Widget:      td.Widget,
+		Group:       td.Group,
+		Order:       td.Order,
+	}
+	if td.Deprecated {
+		v.Deprecated = cmp.Or(td.DeprecatedReason, "deprecated")
+	}
+	switch v.Type {
+	case "list":
+		v.ElemType = schemaType(t.Elem(), td)
+		v.Separator = sliceSep(td)
+	case "map":
+		v.KeyType = schemaType(t.Key(), td)
+		v.ElemType = schemaType(t.Elem(), td)
+		v.Separator = sliceSep(td)
+		v.KVSeparator = mapKVSep(td)
+	}
+	if unitTarget(t) == timeType {
+		v.Layout = timeLayout(td)
+	}
+	if td.Secret {
+		v.Default = redacted
+		v.Example = ""
+	}
+	return v
+}
+
+// schemaType returns the Schema type of values of type t.
+func schemaType(t reflect.Type, td TagData) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case td.JSON:
+		return "json"
+	case t == timeType:
+		return "time"
+	case t == urlType:
+		return "url"
+	case isBytes(t):
+		return "bytes"
+	case isSingleValue(t) || t == fileModeType || t == durationType:
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice:
+		return "list"
+	case reflect.Map:
+		return "map"
+	}
+	return "string"
+}
@@ -0,0 +1,45 @@
+package parsenv
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSchemaJSON(t *testing.T) {
+	var myConfig struct {
+		Port     int               `cfg:"default=8080"`
+		Hosts    []string          `cfg:"required"`
+		Labels   map[string]int    `cfg:"kvsep=:"`
+		Since    *time.Time        `cfg:"layout=2006-01-02"`
+		Token    string            `cfg:"secret;default=dev-token"`
+		Mode     string            `cfg:"oneof=fast|safe;requiredIn=prod"`
+		Upstream map[string]string `cfg:"json"`
+		Cache    ByteSize
+	}
+	out, err := SchemaJSON(&myConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s Schema
+	if err := json.Unmarshal(out, &s); err != nil {
+		t.Fatal(err)
+	}
+	expected := Schema{
+		Version: SchemaVersion,
+		Variables: []SchemaVariable{
+			{Field: "Port", EnvVar: "PORT", Type: "integer", GoType: "int", Default: "8080", Example: "8080"},
+			{Field: "Hosts", EnvVar: "HOSTS", Type: "list", ElemType: "string", GoType: "[]string", Required: true, Example: "example,example", Separator: ","},
+			{Field: "Labels", EnvVar: "LABELS", Type: "map", KeyType: "string", ElemType: "integer", GoType: "map[string]int", Example: "example:1", Separator: ",", KVSeparator: ":"},
+			{Field: "Since", EnvVar: "SINCE", Type: "time", GoType: "*time.Time", Example: "2006-01-02", Layout: "2006-01-02"},
+			{Field: "Token", EnvVar: "TOKEN", Type: "string", GoType: "string", Default: "[REDACTED]", Secret: true},
+			{Field: "Mode", EnvVar: "MODE", Type: "string", GoType: "string", RequiredIn: []string{"prod"}, Example: "fast", OneOf: []string{"fast", "safe"}},
+			{Field: "Upstream", EnvVar: "UPSTREAM", Type: "json", GoType: "map[string]string", Example: "{}"},
+			{Field: "Cache", EnvVar: "CACHE", Type: "string", GoType: "parsenv.ByteSize", Example: "512MiB"},
+		},
+	}
+	if !reflect.DeepEqual(s, expected) {
+		t.Errorf("expected:\n%+v\ngot:\n%+v", expected, s)
+	}
+}
@@ -0,0 +1,321 @@
+package parsenv
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// LoadShellEnvFiles is like LoadDotenvFiles, but for files in the format
+// understood by ParseShellEnv.
+func LoadShellEnvFiles(names ...string) (MapLookuper, error) {
+	vars := MapLookuper{}
+	for _, name := range names {
+		bs, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		fileVars, err := parseShellEnv(string(bs), vars.Lookup, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for key, val := range fileVars {
+			vars[key] = val
+		}
+	}
+	return vars, nil
+}
+
+// ParseShellEnv parses variables from a file meant to be sourced by a POSIX
+// shell (with `source` or `.`), so that existing ops scripts can be used
+// without converting them to dotenv first:
+//
+//	# comments and empty lines are ignored
+//	export DB_HOST="db.internal" DB_PORT=5432
+//	DATA_DIR=$HOME/data; CACHE_DIR="${DATA_DIR}/cache"
+//	GREETING='single quotes are taken '"literally"
+//	JAVA_OPTS="-Xmx2g \
+//	  -Dfile.encoding=UTF-8"
+//
+// Single and double quotes, backslash escapes, and line continuations work
+// like in the shell. References to variables ($VAR, ${VAR}, ${VAR:-default},
+// and ${VAR-default}) are expanded using the variables defined earlier in the
+// file, or else the process environment.
+//
+// Only assignments, optionally preceded by export, are supported. Any other
+// command, as well as command substitution, is an error, because ParseShellEnv
+// doesn't execute anything.
+func ParseShellEnv(r io.Reader) (map[string]string, error) {
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseShellEnv(string(bs), nil, nil)
+}
+
+// shellCommand implements a command that may appear in a shell env file.
+// It can modify vars, which contains the variables defined so far.
+type shellCommand func(vars map[string]string, args []string) error
+
+// parseShellEnv parses the variables defined in src. Variable references are
+// expanded with the variables defined so far, then with lookup (if not nil),
+// then with the process environment. Besides assignments, only the given
+// commands are allowed.
+func parseShellEnv(src string, lookup func(string) (string, bool), commands map[string]shellCommand) (map[string]string, error) {
+	vars := map[string]string{}
+	p := &shellParser{src: src, lookup: func(name string) (string, bool) {
+		if val, ok := vars[name]; ok {
+			return val, true
+		}
+		if lookup != nil {
+			if val, ok := lookup(name); ok {
+				return val, true
+			}
+		}
+		return os.LookupEnv(name)
+	}}
+	for {
+		words, err := p.statement()
+		if err == io.EOF {
+			return vars, nil
+		}
+		if err != nil {
+			return vars, err
+		}
+		if cmd, ok := commands[words[0].value]; ok && !words[0].assign {
+			args := make([]string, len(words)-1)
+			for i, w := range words[1:] {
+				args[i] = w.value
+				if w.assign {
+					args[i] = w.name + "=" + w.value
+				}
+			}
+			err = cmd(vars, args)
+		} else {
+			err = applyShellAssignments(vars, words)
+		}
+		if err != nil {
+			return vars, p.errorfAt(p.stmtStart, "%w", err)
+		}
+	}
+}
+
+// applyShellAssignments applies a statement consisting of assignments,
+// optionally preceded by export.
+func applyShellAssignments(vars map[string]string, words []shellWord) error {
+	if !words[0].assign && words[0].value == "export" {
+		words = words[1:]
+		for _, w := range words {
+			if !w.assign && !isShellName(w.value) {
+				return fmt.Errorf("invalid variable name: %s", w.value)
+			}
+		}
+	} else if !words[0].assign {
+		return fmt.Errorf("unsupported command: %s", words[0].value)
+	} else {
+		for _, w := range words {
+			if !w.assign {
+				return fmt.Errorf("unsupported command: %s", w.value)
+			}
+		}
+	}
+	for _, w := range words {
+		if w.assign {
+			vars[w.name] = w.value
+		}
+	}
+	return nil
+}
+
+// shellWord is a single word of a shell statement, after quote removal and
+// expansion of variables.
+type shellWord struct {
+	assign bool   // the word is an assignment NAME=value
+	name   string // name of the assigned variable
+	value  string
+}
+
+type shellParser struct {
+	src       string
+	pos       int
+	stmtStart int // position of the first word of the current statement
+	lookup    func(string) (string, bool)
+}
+
+func (p *shellParser) errorf(format string, args ...any) error {
+	return p.errorfAt(p.pos, format, args...)
+}
+
+func (p *shellParser) errorfAt(pos int, format string, args ...any) error {
+	line := 1 + strings.Count(p.src[:min(pos, len(p.src))], "\n")
+	return fmt.Errorf("line %d: %w", line, fmt.Errorf(format, args...))
+}
+
+func (p *shellParser) peek(offset int) byte {
+	if p.pos+offset < len(p.src) {
+		return p.src[p.pos+offset]
+	}
+	return 0
+}
+
+// statement parses the words of the next statement. It returns io.EOF if
+// there are no more statements.
+func (p *shellParser) statement() ([]shellWord, error) {
+	var words []shellWord
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == '\n' || c == ';':
+			p.pos++
+			if len(words) > 0 {
+				return words, nil
+			}
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\\' && p.peek(1) == '\n':
+			p.pos += 2
+		case c == '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			if len(words) == 0 {
+				p.stmtStart = p.pos
+			}
+			w, err := p.word()
+			if err != nil {
+				return nil, err
+			}
+			words = append(words, w)
+		}
+	}
+	if len(words) > 0 {
+		return words, nil
+	}
+	return nil, io.EOF
+}
+
+func (p *shellParser) word() (w shellWord, err error) {
+	var val strings.Builder
+	plain := true // no quoting or expansion so far, so the word may still be an assignment
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch c {
+		case ' ', '\t', '\r', '\n', ';':
+			w.value = val.String()
+			return w, nil
+		case '=':
+			p.pos++
+			if plain && !w.assign && isShellName(val.String()) {
+				w.assign = true
+				w.name = val.String()
+				val.Reset()
+			} else {
+				val.WriteByte(c)
+			}
+		case '\\':
+			p.pos++
+			if p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				val.WriteByte(p.src[p.pos])
+				plain = false
+			}
+			p.pos++
+		case '\'':
+			plain = false
+			end := strings.IndexByte(p.src[p.pos+1:], '\'')
+			if end < 0 {
+				return w, p.errorf("unterminated single-quoted string")
+			}
+			val.WriteString(p.src[p.pos+1 : p.pos+1+end])
+			p.pos += end + 2
+		case '"':
+			plain = false
+			if err := p.doubleQuoted(&val); err != nil {
+				return w, err
+			}
+		case '$':
+			plain = false
+			if err := p.expansion(&val); err != nil {
+				return w, err
+			}
+		case '`':
+			return w, p.errorf("command substitution is not supported")
+		default:
+			val.WriteByte(c)
+			p.pos++
+		}
+	}
+	w.value = val.String()
+	return w, nil
+}
+
+func (p *shellParser) doubleQuoted(val *strings.Builder) error {
+	p.pos++ // opening quote
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; c {
+		case '"':
+			p.pos++
+			return nil
+		case '\\':
+			switch next := p.peek(1); next {
+			case '\n':
+			case '$', '`', '"', '\\':
+				val.WriteByte(next)
+			default:
+				val.WriteByte(c)
+				val.WriteByte(next)
+			}
+			p.pos += 2
+		case '$':
+			if err := p.expansion(val); err != nil {
+				return err
+			}
+		case '`':
+			return p.errorf("command substitution is not supported")
+		default:
+			val.WriteByte(c)
+			p.pos++
+		}
+	}
+	return p.errorf("unterminated double-quoted string")
+}
+
+func (p *shellParser) expansion(val *strings.Builder) error {
+	p.pos++ // $
+	switch c := p.peek(0); {
+	case c == '(':
+		return p.errorf("command substitution is not supported")
+	case c == '{':
+		end := strings.IndexByte(p.src[p.pos:], '}')
+		if end < 0 {
+			return p.errorf("unterminated variable reference")
+		}
+		ref := p.src[p.pos+1 : p.pos+end]
+		p.pos += end + 1
+		name, def, hasDef := strings.Cut(ref, "-")
+		emptyIsUnset := hasDef && strings.HasSuffix(name, ":")
+		name = strings.TrimSuffix(name, ":")
+		if !isShellName(name) {
+			return p.errorf("invalid variable reference: ${%s}", ref)
+		}
+		v, ok := p.lookup(name)
+		if hasDef && (!ok || (emptyIsUnset && v == "")) {
+			v = def
+		}
+		val.WriteString(v)
+	case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		start := p.pos
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || (p.src[p.pos] >= 'a' && p.src[p.pos] <= 'z') || (p.src[p.pos] >= 'A' && p.src[p.pos] <= 'Z') || (p.src[p.pos] >= '0' && p.src[p.pos] <= '9')) {
+			p.pos++
+		}
+		v, _ := p.lookup(p.src[start:p.pos])
+		val.WriteString(v)
+	default:
+		val.WriteByte('$')
+	}
+	return nil
+}
+
+func isShellName(name string) bool {
+	return isValidName(name) && !strings.Contains(name, ".")
+}
@@ -0,0 +1,58 @@
+package parsenv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseShellEnv(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	t.Setenv("UNSET_IN_FILE", "")
+	input := `#!/bin/sh
+# database
+export DB_HOST="db.internal" DB_PORT=5432
+DATA_DIR=$HOME/data; CACHE_DIR="${DATA_DIR}/cache"
+GREETING='single quotes are taken '"literally"': $HOME'
+JAVA_OPTS="-Xmx2g \
+  -Dfile.encoding=UTF-8"
+ESCAPED=a\ b\$c
+LEVEL=${LOG_LEVEL:-info} MODE=${UNSET_IN_FILE-fallback}
+export DATA_DIR
+`
+	vars, err := ParseShellEnv(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"DB_HOST":   "db.internal",
+		"DB_PORT":   "5432",
+		"DATA_DIR":  "/home/me/data",
+		"CACHE_DIR": "/home/me/data/cache",
+		"GREETING":  "single quotes are taken literally: $HOME",
+		"JAVA_OPTS": "-Xmx2g   -Dfile.encoding=UTF-8",
+		"ESCAPED":   "a b$c",
+		"LEVEL":     "info",
+		"MODE":      "",
+	}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Errorf("expected %#v, got: %#v", expected, vars)
+	}
+}
+
+func TestParseShellEnvErrors(t *testing.T) {
+	for input, expected := range map[string]string{
+		"FOO=bar\necho hello":    "line 2: unsupported command: echo",
+		"FOO=$(whoami)":          "line 1: command substitution is not supported",
+		"FOO=`whoami`":           "line 1: command substitution is not supported",
+		"FOO='unterminated":      "line 1: unterminated single-quoted string",
+		"\n\nFOO=\"unterminated": "line 3: unterminated double-quoted string",
+		"FOO=bar make install":   "line 1: unsupported command: make",
+		"export 1NVALID":         "line 1: invalid variable name: 1NVALID",
+	} {
+		_, err := ParseShellEnv(strings.NewReader(input))
+		if err == nil || err.Error() != expected {
+			t.Errorf("%q: expected error %q, got: %v", input, expected, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package parsenv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// A Simulation is the outcome of a dry run of Load, see Simulate.
+type Simulation struct {
+	// Report tells how each field would be populated.
+	Report Report
+
+	// Warnings are the problems Load would pass to Options.Warn.
+	Warnings []error
+
+	// Err is the error Load would return.
+	Err error
+}
+
+// Simulate reports how Load would populate cfg from the variables of l,
+// without modifying cfg, e.g. to review a new env file before deploying it:
+//
+//	env, err := parsenv.LoadDotenvFiles("new.env")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	sim := parsenv.Simulate(&config.Config{}, env)
+//	fmt.Print(sim)
+//
+// The parsenv command does that for the config structs of the packages
+// given on the command line:
+//
+//	go run github.com/cvanloo/parsenv/cmd/parsenv simulate --env-file new.env ./...
+//
+// The process environment is not consulted (unless l does so), nor are
+// variables of fields tagged `unset` removed from it, and nobody is prompted
+// for missing variables. cfg may be a struct or a pointer to a
+// struct, otherwise Simulate panics.
+func Simulate(cfg any, l Lookuper, opts ...Option) *Simulation {
+	t := reflect.TypeOf(cfg)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		panic("parsenv.Simulate: must pass a structure or a pointer to a structure")
+	}
+	sim := &Simulation{}
+	o := makeOptions(opts)
+	o.Lookuper = l
+	o.Secrets = nil
+	o.Prompter = nil
+	o.Report = &sim.Report
+	o.simulate = true
+	warn := o.Warn
+	o.Warn = func(err error) {
+		sim.Warnings = append(sim.Warnings, err)
+		if warn != nil {
+			warn(err)
+		}
+	}
+	sim.Err = newLoadError(loadStruct(reflect.New(t).Elem(), o), o)
+	return sim
+}
+
+// String lists the variables with the source and value they would have,
+// followed by the warnings and errors, if any.
+func (s *Simulation) String() string {
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	for _, f := range s.Report.Fields {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", f.Name, f.Source, f.Value)
+	}
+	tw.Flush()
+	for _, w := range s.Warnings {
+		fmt.Fprintf(&sb, "warning: %v\n", w)
+	}
+	if s.Err != nil {
+		fmt.Fprintf(&sb, "error: %v\n", s.Err)
+	}
+	return sb.String()
+}
@@ -0,0 +1,53 @@
+package parsenv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSimulate(t *testing.T) {
+	type config struct {
+		Host     string `cfg:"default=localhost"`
+		Port     int
+		Password string `cfg:"required;secret"`
+		Sentry   string `cfg:"recommended"`
+	}
+	t.Setenv("PASSWORD", "from-the-process-env")
+	cfg := config{Host: "unchanged"}
+
+	sim := Simulate(&cfg, MapLookuper{"PORT": "80"})
+	if cfg.Host != "unchanged" {
+		t.Errorf("expected cfg to be left alone, got: %+v", cfg)
+	}
+	var merr *MissingError
+	if !errors.As(sim.Err, &merr) || merr.Name != "PASSWORD" {
+		t.Errorf("expected a *MissingError for PASSWORD, got: %v", sim.Err)
+	}
+	var rw *RecommendedWarning
+	if len(sim.Warnings) != 1 || !errors.As(sim.Warnings[0], &rw) || rw.Name != "SENTRY" {
+		t.Errorf("expected a *RecommendedWarning for SENTRY, got: %v", sim.Warnings)
+	}
+	sources := map[string]ValueSource{}
+	for _, f := range sim.Report.Fields {
+		sources[f.Name] = f.Source
+	}
+	if sources["HOST"] != SourceDefault || sources["PORT"] != SourceEnv || sources["PASSWORD"] != SourceUnset {
+		t.Errorf("unexpected sources: %v", sources)
+	}
+
+	sim = Simulate(config{}, MapLookuper{"PORT": "eighty", "PASSWORD": "hunter2"})
+	var perr *ParseError
+	if !errors.As(sim.Err, &perr) || perr.Name != "PORT" {
+		t.Errorf("expected a *ParseError for PORT, got: %v", sim.Err)
+	}
+	out := sim.String()
+	for _, expected := range []string{"HOST", "default", "localhost", "[REDACTED]", "warning: ", "error: "} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in the output, got:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("expected the secret to be redacted, got:\n%s", out)
+	}
+}
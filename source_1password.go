@@ -0,0 +1,200 @@
+package parsenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// OnePassword is a Source that resolves 1Password secret references of the
+// form op://vault/item/[section/]field in the values of another Lookuper,
+// similar to `op run`. Values that aren't secret references are returned
+// unchanged. Pass it with WithSecrets, so that only the values of fields
+// tagged `secret` are resolved, while all other fields are loaded as they
+// are:
+//
+//	// DB_HOST=db.internal
+//	// DB_PASSWORD=op://prod/postgres/password
+//	var cfg struct {
+//		DBHost     string
+//		DBPassword string `cfg:"secret"`
+//	}
+//	err := parsenv.Load(&cfg, parsenv.WithSecrets(parsenv.OnePassword{}))
+//
+// References are resolved with `op read`, unless ConnectHost is set, in which
+// case the 1Password Connect server at that address is queried instead.
+type OnePassword struct {
+	// Base is where values are looked up. If nil, the process environment
+	// is used.
+	Base Lookuper
+
+	// ConnectHost is the URL of a 1Password Connect server, e.g.
+	// http://op-connect:8080. If empty, the op CLI is used.
+	ConnectHost string
+
+	// ConnectToken is the access token for the Connect server.
+	ConnectToken string
+
+	// Client is used for requests to the Connect server. If nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+}
+
+// Lookup is like LookupErr, but treats errors as absent values.
+func (p OnePassword) Lookup(name string) (string, bool) {
+	val, ok, _ := p.LookupErr(name)
+	return val, ok
+}
+
+// LookupErr looks up name in p.Base and resolves the value if it is a secret
+// reference.
+func (p OnePassword) LookupErr(name string) (string, bool, error) {
+	base := p.Base
+	if base == nil {
+		base = EnvLookuper
+	}
+	val, ok, err := lookupErr(base, name)
+	if err != nil || !ok || !strings.HasPrefix(val, "op://") {
+		return val, ok, err
+	}
+	if p.ConnectHost != "" {
+		val, err = p.readConnect(val)
+	} else {
+		val, err = readOpCLI(val)
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return val, true, nil
+}
+
+func readOpCLI(ref string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("op", "read", "--no-newline", ref)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("op read %s: %w: %s", ref, err, msg)
+		}
+		return "", fmt.Errorf("op read %s: %w", ref, err)
+	}
+	return string(out), nil
+}
+
+type opReference struct {
+	vault, item, section, field string
+}
+
+func parseOpReference(ref string) (r opReference, err error) {
+	parts := strings.Split(strings.TrimPrefix(ref, "op://"), "/")
+	switch len(parts) {
+	default:
+		return r, fmt.Errorf("invalid secret reference: %s", ref)
+	case 3:
+		r = opReference{vault: parts[0], item: parts[1], field: parts[2]}
+	case 4:
+		r = opReference{vault: parts[0], item: parts[1], section: parts[2], field: parts[3]}
+	}
+	if r.vault == "" || r.item == "" || r.field == "" {
+		return r, fmt.Errorf("invalid secret reference: %s", ref)
+	}
+	return r, nil
+}
+
+type opConnectObject struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Title string `json:"title"`
+	Label string `json:"label"`
+}
+
+type opConnectItem struct {
+	Sections []opConnectObject `json:"sections"`
+	Fields   []struct {
+		opConnectObject
+		Value   string           `json:"value"`
+		Section *opConnectObject `json:"section"`
+	} `json:"fields"`
+}
+
+func (p OnePassword) readConnect(ref string) (string, error) {
+	r, err := parseOpReference(ref)
+	if err != nil {
+		return "", err
+	}
+	vaultID, err := p.connectFind("/v1/vaults", "name", r.vault)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", ref, err)
+	}
+	itemsPath := "/v1/vaults/" + url.PathEscape(vaultID) + "/items"
+	itemID, err := p.connectFind(itemsPath, "title", r.item)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", ref, err)
+	}
+	var item opConnectItem
+	if err := p.connectGet(itemsPath+"/"+url.PathEscape(itemID), &item); err != nil {
+		return "", fmt.Errorf("resolving %s: %w", ref, err)
+	}
+	sectionIDs := map[string]string{}
+	for _, s := range item.Sections {
+		sectionIDs[s.ID] = s.Label
+	}
+	for _, f := range item.Fields {
+		if f.Label != r.field && f.ID != r.field {
+			continue
+		}
+		if r.section != "" && (f.Section == nil || (f.Section.ID != r.section && sectionIDs[f.Section.ID] != r.section)) {
+			continue
+		}
+		return f.Value, nil
+	}
+	return "", fmt.Errorf("resolving %s: field not found", ref)
+}
+
+// connectFind returns the ID of the object at path whose attr equals
+// nameOrID. If no such object exists, nameOrID is assumed to be an ID.
+func (p OnePassword) connectFind(path, attr, nameOrID string) (string, error) {
+	var objs []opConnectObject
+	filter := url.Values{"filter": {fmt.Sprintf("%s eq %q", attr, nameOrID)}}
+	if err := p.connectGet(path+"?"+filter.Encode(), &objs); err != nil {
+		return "", err
+	}
+	if len(objs) == 0 {
+		return nameOrID, nil
+	}
+	return objs[0].ID, nil
+}
+
+func (p OnePassword) connectGet(path string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(p.ConnectHost, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.ConnectToken)
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Message != "" {
+			return errors.New(apiErr.Message)
+		}
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,103 @@
+package parsenv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestOnePasswordCLI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake op CLI is a shell script")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\n[ \"$3\" = op://prod/postgres/password ] && printf hunter2 && exit 0\necho \"[ERROR] could not read secret\" >&2\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(dir, "op"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var myConfig struct {
+		Host     string
+		Password string `cfg:"secret"`
+		Token    string `cfg:"secret"`
+	}
+	base := MapLookuper{
+		"HOST":     "db.internal",
+		"PASSWORD": "op://prod/postgres/password",
+		"TOKEN":    "op://prod/api/token",
+	}
+	err := Load(&myConfig, WithLookuper(OnePassword{Base: base}))
+	if details := ErrorDetails(err); len(details) != 1 || details[0].EnvVar != "TOKEN" {
+		t.Errorf("expected a single error for TOKEN, got: %v", err)
+	}
+	if myConfig.Host != "db.internal" || myConfig.Password != "hunter2" {
+		t.Errorf("unexpected config: %#v", myConfig)
+	}
+}
+
+func TestOnePasswordConnect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/vaults", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":401,"message":"Invalid token signature"}`))
+			return
+		}
+		if r.URL.Query().Get("filter") == `name eq "prod"` {
+			w.Write([]byte(`[{"id":"v1","name":"prod"}]`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("GET /v1/vaults/v1/items", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":"i1","title":"postgres"}]`))
+	})
+	mux.HandleFunc("GET /v1/vaults/v1/items/i1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"sections": [{"id":"s1","label":"replica"}],
+			"fields": [
+				{"id":"password","label":"password","value":"hunter2"},
+				{"id":"f2","label":"password","value":"replica-pw","section":{"id":"s1"}}
+			]
+		}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p := OnePassword{
+		Base: MapLookuper{
+			"PRIMARY": "op://prod/postgres/password",
+			"REPLICA": "op://prod/postgres/replica/password",
+		},
+		ConnectHost:  srv.URL,
+		ConnectToken: "secret-token",
+	}
+	if val, _, err := p.LookupErr("PRIMARY"); err != nil || val != "hunter2" {
+		t.Errorf("expected hunter2, got: %q (%v)", val, err)
+	}
+	if val, _, err := p.LookupErr("REPLICA"); err != nil || val != "replica-pw" {
+		t.Errorf("expected replica-pw, got: %q (%v)", val, err)
+	}
+
+	base := MapLookuper{"PRIMARY": "op://prod/postgres/password", "EXAMPLE": "op://prod/postgres/password"}
+	p.Base = base
+	var myConfig struct {
+		Primary string `cfg:"secret"`
+		Example string
+	}
+	if err := Load(&myConfig, WithLookuper(base), WithSecrets(p)); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.Primary != "hunter2" || myConfig.Example != "op://prod/postgres/password" {
+		t.Errorf("expected only the secret field to be resolved, got: %#v", myConfig)
+	}
+
+	p.ConnectToken = "wrong"
+	if _, _, err := p.LookupErr("PRIMARY"); err == nil {
+		t.Error("expected non-nil error, got nil")
+	}
+}
@@ -0,0 +1,149 @@
+package parsenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// AWSMetadata is a Source exposing metadata about the AWS platform a service
+// runs on under predictable names, so that config structs can simply declare
+// fields like AwsRegion or AwsEcsTaskArn:
+//
+//	AWS_REGION                   region, e.g. eu-central-1
+//	AWS_ACCOUNT_ID               account ID (ECS only)
+//	AWS_AVAILABILITY_ZONE        availability zone (ECS only)
+//	AWS_ECS_CLUSTER              cluster ARN or name
+//	AWS_ECS_TASK_ARN             ARN of the running task
+//	AWS_ECS_TASK_FAMILY          family of the task definition
+//	AWS_ECS_TASK_REVISION        revision of the task definition
+//	AWS_LAMBDA_FUNCTION_NAME     name of the Lambda function
+//	AWS_LAMBDA_FUNCTION_VERSION  version of the Lambda function
+//
+// On ECS, values are retrieved from the task metadata endpoint (v4), which is
+// queried until that succeeds once. On Lambda, the variables set by the runtime are used.
+// All other names are absent, so AWSMetadata is meant to be combined with
+// another Lookuper:
+//
+//	l := parsenv.MultiLookuper(parsenv.EnvLookuper, &parsenv.AWSMetadata{})
+//	err := parsenv.Load(&cfg, parsenv.WithLookuper(l))
+type AWSMetadata struct {
+	// Base is where the variables provided by the platform (such as
+	// ECS_CONTAINER_METADATA_URI_V4 or AWS_REGION) are looked up. If nil,
+	// the process environment is used.
+	Base Lookuper
+
+	// Client is used to query the task metadata endpoint. If nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+
+	mu      sync.Mutex
+	fetched bool
+	task    ecsTaskMetadata
+}
+
+type ecsTaskMetadata struct {
+	Cluster          string
+	TaskARN          string
+	Family           string
+	Revision         string
+	AvailabilityZone string
+}
+
+// Lookup is like LookupErr, but treats errors as absent values.
+func (m *AWSMetadata) Lookup(name string) (string, bool) {
+	val, ok, _ := m.LookupErr(name)
+	return val, ok
+}
+
+// LookupErr returns the metadata value called name.
+func (m *AWSMetadata) LookupErr(name string) (string, bool, error) {
+	base := m.Base
+	if base == nil {
+		base = EnvLookuper
+	}
+	switch name {
+	case "AWS_LAMBDA_FUNCTION_NAME", "AWS_LAMBDA_FUNCTION_VERSION":
+		return lookupErr(base, name)
+	case "AWS_REGION":
+		for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+			if val, ok, err := lookupErr(base, name); err != nil || ok {
+				return val, ok, err
+			}
+		}
+	case "AWS_ACCOUNT_ID", "AWS_AVAILABILITY_ZONE", "AWS_ECS_CLUSTER", "AWS_ECS_TASK_ARN", "AWS_ECS_TASK_FAMILY", "AWS_ECS_TASK_REVISION":
+	default:
+		return "", false, nil
+	}
+
+	endpoint, ok, err := lookupErr(base, "ECS_CONTAINER_METADATA_URI_V4")
+	if err != nil || !ok || endpoint == "" {
+		return "", false, err
+	}
+	task, err := m.taskMetadata(endpoint)
+	if err != nil {
+		return "", false, err
+	}
+
+	// arn:aws:ecs:<region>:<account>:task/<cluster>/<id>
+	arn := strings.Split(task.TaskARN, ":")
+	var val string
+	switch name {
+	case "AWS_REGION":
+		if len(arn) > 3 {
+			val = arn[3]
+		}
+	case "AWS_ACCOUNT_ID":
+		if len(arn) > 4 {
+			val = arn[4]
+		}
+	case "AWS_AVAILABILITY_ZONE":
+		val = task.AvailabilityZone
+	case "AWS_ECS_CLUSTER":
+		val = task.Cluster
+	case "AWS_ECS_TASK_ARN":
+		val = task.TaskARN
+	case "AWS_ECS_TASK_FAMILY":
+		val = task.Family
+	case "AWS_ECS_TASK_REVISION":
+		val = task.Revision
+	}
+	return val, val != "", nil
+}
+
+// taskMetadata returns the task metadata, querying the endpoint if that
+// hasn't succeeded yet. Failed queries are retried by the next call.
+func (m *AWSMetadata) taskMetadata(endpoint string) (ecsTaskMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fetched {
+		return m.task, nil
+	}
+	task, err := m.fetchTaskMetadata(endpoint)
+	if err != nil {
+		return task, err
+	}
+	m.task, m.fetched = task, true
+	return task, nil
+}
+
+func (m *AWSMetadata) fetchTaskMetadata(endpoint string) (task ecsTaskMetadata, err error) {
+	client := m.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(strings.TrimSuffix(endpoint, "/") + "/task")
+	if err != nil {
+		return task, fmt.Errorf("ecs task metadata: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return task, fmt.Errorf("ecs task metadata: unexpected status: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&task); err != nil {
+		return task, fmt.Errorf("ecs task metadata: %w", err)
+	}
+	return task, nil
+}
@@ -0,0 +1,116 @@
+package parsenv
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAWSMetadataECS(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v4/abc/task" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{
+			"Cluster": "arn:aws:ecs:eu-central-1:123456789012:cluster/prod",
+			"TaskARN": "arn:aws:ecs:eu-central-1:123456789012:task/prod/0123abcd",
+			"Family": "billing",
+			"Revision": "42",
+			"AvailabilityZone": "eu-central-1a"
+		}`))
+	}))
+	defer srv.Close()
+
+	var myConfig struct {
+		AwsRegion             string
+		AwsAccountId          string
+		AwsEcsTaskArn         string
+		AwsEcsTaskRevision    int
+		AwsAvailabilityZone   string
+		AwsLambdaFunctionName string
+	}
+	m := &AWSMetadata{Base: MapLookuper{"ECS_CONTAINER_METADATA_URI_V4": srv.URL + "/v4/abc"}}
+	if err := Load(&myConfig, WithLookuper(m)); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.AwsRegion != "eu-central-1" || myConfig.AwsAccountId != "123456789012" || myConfig.AwsEcsTaskRevision != 42 || myConfig.AwsAvailabilityZone != "eu-central-1a" {
+		t.Errorf("unexpected config: %#v", myConfig)
+	}
+	if myConfig.AwsEcsTaskArn != "arn:aws:ecs:eu-central-1:123456789012:task/prod/0123abcd" {
+		t.Errorf("unexpected task ARN: %s", myConfig.AwsEcsTaskArn)
+	}
+	if requests != 1 {
+		t.Errorf("expected metadata endpoint to be queried once, got: %d", requests)
+	}
+}
+
+func TestAWSMetadataRetry(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"TaskARN": "arn:aws:ecs:eu-central-1:123456789012:task/prod/0123abcd"}`))
+	}))
+	defer srv.Close()
+
+	m := &AWSMetadata{Base: MapLookuper{"ECS_CONTAINER_METADATA_URI_V4": srv.URL}}
+	if _, _, err := m.LookupErr("AWS_REGION"); err == nil {
+		t.Error("expected an error for the failed query, got nil")
+	}
+	for range 2 {
+		if val, _, err := m.LookupErr("AWS_REGION"); err != nil || val != "eu-central-1" {
+			t.Errorf("expected eu-central-1, got: %q (%v)", val, err)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("expected the failed query to be retried once, got %d requests", requests)
+	}
+}
+
+func TestAWSMetadataLambda(t *testing.T) {
+	m := &AWSMetadata{Base: MapLookuper{
+		"AWS_REGION":               "us-east-1",
+		"AWS_LAMBDA_FUNCTION_NAME": "resize-images",
+	}}
+	if val, _ := m.Lookup("AWS_REGION"); val != "us-east-1" {
+		t.Errorf("expected us-east-1, got: %s", val)
+	}
+	if val, _ := m.Lookup("AWS_LAMBDA_FUNCTION_NAME"); val != "resize-images" {
+		t.Errorf("expected resize-images, got: %s", val)
+	}
+	if _, ok := m.Lookup("AWS_ECS_TASK_ARN"); ok {
+		t.Error("expected AWS_ECS_TASK_ARN to be absent outside of ECS")
+	}
+	if _, ok := m.Lookup("HOME"); ok {
+		t.Error("expected unrelated variables to be absent")
+	}
+}
+
+func TestAWSMetadataConcurrent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"TaskARN": "arn:aws:ecs:eu-central-1:123456789012:task/prod/0123abcd"}`))
+	}))
+	defer srv.Close()
+
+	m := &AWSMetadata{Base: MapLookuper{"ECS_CONTAINER_METADATA_URI_V4": srv.URL}}
+	concurrently(t, 20, func() error {
+		var myConfig struct {
+			AwsRegion    string
+			AwsAccountId string
+		}
+		if err := Load(&myConfig, WithLookuper(m)); err != nil {
+			return err
+		}
+		if myConfig.AwsRegion != "eu-central-1" || myConfig.AwsAccountId != "123456789012" {
+			return fmt.Errorf("unexpected config: %#v", myConfig)
+		}
+		return nil
+	})
+}
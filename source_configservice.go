@@ -0,0 +1,188 @@
+package parsenv
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"sync"
+)
+
+// A ConfigServiceClient is the client side of an internal config service,
+// typically speaking a small gRPC protocol like:
+//
+//	service ConfigService {
+//		rpc GetValues(GetValuesRequest) returns (GetValuesResponse);
+//		rpc WatchValues(WatchValuesRequest) returns (stream ValuesUpdate);
+//	}
+//	message GetValuesRequest { repeated string keys = 1; }
+//	message GetValuesResponse { map<string, string> values = 1; }
+//	message WatchValuesRequest { string prefix = 1; }
+//	message ValuesUpdate { map<string, string> values = 1; repeated string deleted = 2; }
+//
+// parsenv doesn't depend on gRPC itself; implement this interface with a thin
+// adapter around the generated client stub.
+type ConfigServiceClient interface {
+	// GetValues returns the values of the given keys. Keys that don't exist
+	// are omitted from the result.
+	GetValues(ctx context.Context, keys []string) (map[string]string, error)
+
+	// WatchValues streams updates of all keys starting with prefix, calling
+	// onUpdate for each of them, until ctx is canceled or the stream fails.
+	// values holds the keys that were set, with their new values, and
+	// deleted the keys that were removed.
+	WatchValues(ctx context.Context, prefix string, onUpdate func(values map[string]string, deleted []string)) error
+}
+
+// ConfigServiceSource is a Source backed by a config service. Variable names
+// are prefixed with Prefix to form the keys used in the service.
+//
+// Values are requested on demand (use Fetch to request several of them in a
+// single round trip) and cached. Run Watch in the background to receive
+// updates via streaming, instead of polling:
+//
+//	src := &parsenv.ConfigServiceSource{Client: adapter{pb.NewConfigServiceClient(conn)}, Prefix: "billing/"}
+//	go src.Watch(ctx, func() { /* reload */ })
+//
+// A ConfigServiceSource is safe for concurrent use.
+type ConfigServiceSource struct {
+	Client ConfigServiceClient
+	Prefix string
+
+	mu     sync.RWMutex
+	values map[string]string // cached values, by name (without prefix)
+	absent map[string]bool   // names known not to exist
+}
+
+// Lookup is like LookupErr, but treats errors as absent values.
+func (s *ConfigServiceSource) Lookup(name string) (string, bool) {
+	val, ok, _ := s.LookupErr(name)
+	return val, ok
+}
+
+// LookupErr returns the value of the variable name, requesting it from the
+// service if it isn't cached yet.
+func (s *ConfigServiceSource) LookupErr(name string) (string, bool, error) {
+	s.mu.RLock()
+	val, ok := s.values[name]
+	known := ok || s.absent[name]
+	s.mu.RUnlock()
+	if known {
+		return val, ok, nil
+	}
+	if err := s.Fetch(context.Background(), name); err != nil {
+		return "", false, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, ok = s.values[name]
+	return val, ok, nil
+}
+
+// Fetch requests the values of the given variables in a single call and
+// caches them.
+func (s *ConfigServiceSource) Fetch(ctx context.Context, names ...string) error {
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = s.Prefix + name
+	}
+	values, err := s.Client.GetValues(ctx, keys)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.init()
+	for _, name := range names {
+		if val, ok := values[s.Prefix+name]; ok {
+			s.values[name] = val
+			delete(s.absent, name)
+		} else {
+			delete(s.values, name)
+			s.absent[name] = true
+		}
+	}
+	return nil
+}
+
+// Snapshot returns the cached values as they are now. Variables that aren't
+// cached yet are requested when they are first looked up in the snapshot,
+// and don't change for it afterwards either. Fetch them beforehand to have
+// all values in the snapshot come from the same point in time.
+func (s *ConfigServiceSource) Snapshot() (Lookuper, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snap := &configServiceSnapshot{src: s, values: maps.Clone(s.values), absent: maps.Clone(s.absent)}
+	if snap.values == nil {
+		snap.values = map[string]string{}
+		snap.absent = map[string]bool{}
+	}
+	return snap, nil
+}
+
+// Watch streams updates from the service into the cache, calling onChange
+// (if not nil) after each update that changed a value, until ctx is canceled
+// or the stream fails.
+func (s *ConfigServiceSource) Watch(ctx context.Context, onChange func()) error {
+	return s.Client.WatchValues(ctx, s.Prefix, func(values map[string]string, deleted []string) {
+		s.mu.Lock()
+		s.init()
+		before := maps.Clone(s.values)
+		for key, val := range values {
+			if name, ok := strings.CutPrefix(key, s.Prefix); ok {
+				s.values[name] = val
+				delete(s.absent, name)
+			}
+		}
+		for _, key := range deleted {
+			if name, ok := strings.CutPrefix(key, s.Prefix); ok {
+				delete(s.values, name)
+				s.absent[name] = true
+			}
+		}
+		changed := !maps.Equal(before, s.values)
+		s.mu.Unlock()
+		if changed && onChange != nil {
+			onChange()
+		}
+	})
+}
+
+func (s *ConfigServiceSource) init() {
+	if s.values == nil {
+		s.values = map[string]string{}
+		s.absent = map[string]bool{}
+	}
+}
+
+// configServiceSnapshot is a Source serving the values of a
+// ConfigServiceSource at the time of the snapshot.
+type configServiceSnapshot struct {
+	src *ConfigServiceSource
+
+	mu     sync.Mutex
+	values map[string]string
+	absent map[string]bool
+}
+
+func (s *configServiceSnapshot) Lookup(name string) (string, bool) {
+	val, ok, _ := s.LookupErr(name)
+	return val, ok
+}
+
+func (s *configServiceSnapshot) LookupErr(name string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if val, ok := s.values[name]; ok || s.absent[name] {
+		return val, ok, nil
+	}
+	val, ok, err := s.src.LookupErr(name)
+	if err != nil {
+		return "", false, err
+	}
+	if ok {
+		s.values[name] = val
+	} else {
+		s.absent[name] = true
+	}
+	return val, ok, nil
+}
@@ -0,0 +1,106 @@
+package parsenv
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type fakeConfigService struct {
+	values  map[string]string
+	calls   [][]string
+	updates chan map[string]string
+
+	mu sync.Mutex // guards values and calls
+}
+
+func (f *fakeConfigService) GetValues(ctx context.Context, keys []string) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, keys)
+	values := map[string]string{}
+	for _, key := range keys {
+		if val, ok := f.values[key]; ok {
+			values[key] = val
+		}
+	}
+	return values, nil
+}
+
+func (f *fakeConfigService) WatchValues(ctx context.Context, prefix string, onUpdate func(map[string]string, []string)) error {
+	for update := range f.updates {
+		onUpdate(update, []string{prefix + "DEBUG"})
+	}
+	return nil
+}
+
+func TestConfigServiceSource(t *testing.T) {
+	client := &fakeConfigService{values: map[string]string{
+		"billing/HOST":  "db.internal",
+		"billing/DEBUG": "true",
+		"other/HOST":    "other.internal",
+	}}
+	src := &ConfigServiceSource{Client: client, Prefix: "billing/"}
+
+	if err := src.Fetch(context.Background(), "HOST", "PORT", "DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	var myConfig struct {
+		Host  string
+		Port  int `cfg:"default=5432"`
+		Debug bool
+	}
+	if err := Load(&myConfig, WithLookuper(src)); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.Host != "db.internal" || myConfig.Port != 5432 || !myConfig.Debug {
+		t.Errorf("unexpected config: %#v", myConfig)
+	}
+	if expected := [][]string{{"billing/HOST", "billing/PORT", "billing/DEBUG"}}; !reflect.DeepEqual(client.calls, expected) {
+		t.Errorf("expected a single batched call, got: %v", client.calls)
+	}
+
+	client.updates = make(chan map[string]string, 2)
+	client.updates <- map[string]string{"billing/HOST": "db2.internal"}
+	client.updates <- map[string]string{"billing/HOST": "db2.internal"}
+	close(client.updates)
+	changes := 0
+	src.Watch(context.Background(), func() { changes++ })
+	if changes != 1 {
+		t.Errorf("expected 1 change, got: %d", changes)
+	}
+	if val, _ := src.Lookup("HOST"); val != "db2.internal" {
+		t.Errorf("expected db2.internal, got: %s", val)
+	}
+	if _, ok := src.Lookup("DEBUG"); ok {
+		t.Error("expected DEBUG to be deleted")
+	}
+}
+
+func TestConfigServiceSourceConcurrent(t *testing.T) {
+	client := &fakeConfigService{
+		values:  map[string]string{"billing/VERSION": "0"},
+		updates: make(chan map[string]string),
+	}
+	src := &ConfigServiceSource{Client: client, Prefix: "billing/"}
+	done := make(chan error)
+	go func() { done <- src.Watch(context.Background(), nil) }()
+	var version atomic.Int32
+	concurrently(t, 50, func() error {
+		client.updates <- map[string]string{"billing/VERSION": fmt.Sprint(version.Add(1))}
+		var myConfig struct {
+			Version int
+			Host    string `cfg:"default=localhost"`
+		}
+		if err := Load(&myConfig, WithLookuper(src)); err != nil {
+			return err
+		}
+		_, err := src.Snapshot()
+		return err
+	})
+	close(client.updates)
+	<-done
+}
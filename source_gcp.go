@@ -0,0 +1,152 @@
+package parsenv
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// GoogleCloud is a Source recognizing the built-in variables of Cloud Run
+// (services and jobs) and App Engine, and exposing them under stable names,
+// independent of the platform:
+//
+//	GCP_PLATFORM  cloudrun, cloudrun-job, or appengine
+//	GCP_SERVICE   K_SERVICE, CLOUD_RUN_JOB, or GAE_SERVICE
+//	GCP_REVISION  K_REVISION, CLOUD_RUN_EXECUTION, or GAE_VERSION
+//	GCP_INSTANCE  GAE_INSTANCE
+//	GCP_PROJECT   GOOGLE_CLOUD_PROJECT, or from the metadata server
+//	GCP_REGION    from the metadata server
+//	PORT          PORT, or 8080 on Cloud Run and App Engine
+//
+// Values from the metadata server are only requested when running on one of
+// the platforms, and each of them at most once.
+// All other names are absent, so GoogleCloud is meant to be combined with
+// another Lookuper:
+//
+//	l := parsenv.MultiLookuper(parsenv.EnvLookuper, &parsenv.GoogleCloud{})
+//	err := parsenv.Load(&cfg, parsenv.WithLookuper(l))
+type GoogleCloud struct {
+	// Base is where the variables set by the platform are looked up. If nil,
+	// the process environment is used.
+	Base Lookuper
+
+	// MetadataHost is the URL of the metadata server. If empty,
+	// http://metadata.google.internal is used.
+	MetadataHost string
+
+	// Client is used to query the metadata server. If nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+
+	mu       sync.Mutex
+	metadata map[string]string
+}
+
+// Lookup is like LookupErr, but treats errors as absent values.
+func (g *GoogleCloud) Lookup(name string) (string, bool) {
+	val, ok, _ := g.LookupErr(name)
+	return val, ok
+}
+
+// LookupErr returns the platform value called name.
+func (g *GoogleCloud) LookupErr(name string) (string, bool, error) {
+	base := g.Base
+	if base == nil {
+		base = EnvLookuper
+	}
+	first := func(names ...string) (string, bool, error) {
+		for _, name := range names {
+			if val, ok, err := lookupErr(base, name); err != nil || (ok && val != "") {
+				return val, ok, err
+			}
+		}
+		return "", false, nil
+	}
+	platform, err := g.platform(base)
+	if err != nil {
+		return "", false, err
+	}
+	switch name {
+	case "GCP_PLATFORM":
+		return platform, platform != "", nil
+	case "GCP_SERVICE":
+		return first("K_SERVICE", "CLOUD_RUN_JOB", "GAE_SERVICE")
+	case "GCP_REVISION":
+		return first("K_REVISION", "CLOUD_RUN_EXECUTION", "GAE_VERSION")
+	case "GCP_INSTANCE":
+		return first("GAE_INSTANCE")
+	case "GCP_PROJECT":
+		if val, ok, err := first("GOOGLE_CLOUD_PROJECT"); err != nil || ok || platform == "" {
+			return val, ok, err
+		}
+		return g.fetchMetadata("project/project-id")
+	case "GCP_REGION":
+		if platform == "" {
+			return "", false, nil
+		}
+		region, ok, err := g.fetchMetadata("instance/region")
+		// projects/<number>/regions/<region>
+		return path.Base(region), ok, err
+	case "PORT":
+		if val, ok, err := first("PORT"); err != nil || ok || platform == "" || platform == "cloudrun-job" {
+			return val, ok, err
+		}
+		return "8080", true, nil
+	}
+	return "", false, nil
+}
+
+func (g *GoogleCloud) platform(base Lookuper) (string, error) {
+	for _, p := range []struct{ name, platform string }{
+		{"K_SERVICE", "cloudrun"},
+		{"CLOUD_RUN_JOB", "cloudrun-job"},
+		{"GAE_SERVICE", "appengine"},
+	} {
+		if val, ok, err := lookupErr(base, p.name); err != nil || (ok && val != "") {
+			return p.platform, err
+		}
+	}
+	return "", nil
+}
+
+func (g *GoogleCloud) fetchMetadata(key string) (string, bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if val, ok := g.metadata[key]; ok {
+		return val, true, nil
+	}
+	host := g.MetadataHost
+	if host == "" {
+		host = "http://metadata.google.internal"
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(host, "/")+"/computeMetadata/v1/"+key, nil)
+	if err != nil {
+		return "", false, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", false, fmt.Errorf("gcp metadata: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", false, fmt.Errorf("gcp metadata: %s: unexpected status: %s", key, resp.Status)
+	}
+	bs, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", false, fmt.Errorf("gcp metadata: %w", err)
+	}
+	if g.metadata == nil {
+		g.metadata = map[string]string{}
+	}
+	val := strings.TrimSpace(string(bs))
+	g.metadata[key] = val
+	return val, true, nil
+}
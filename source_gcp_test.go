@@ -0,0 +1,96 @@
+package parsenv
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGoogleCloudRun(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/computeMetadata/v1/project/project-id":
+			w.Write([]byte("my-project"))
+		case "/computeMetadata/v1/instance/region":
+			w.Write([]byte("projects/123456/regions/europe-west6"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	var myConfig struct {
+		GcpPlatform string
+		GcpService  string
+		GcpRevision string
+		GcpProject  string
+		GcpRegion   string
+		Port        int
+	}
+	g := &GoogleCloud{
+		Base: MapLookuper{
+			"K_SERVICE":  "billing",
+			"K_REVISION": "billing-00042-abc",
+		},
+		MetadataHost: srv.URL,
+	}
+	for range 2 {
+		if err := Load(&myConfig, WithLookuper(g)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if myConfig.GcpPlatform != "cloudrun" || myConfig.GcpService != "billing" || myConfig.GcpRevision != "billing-00042-abc" {
+		t.Errorf("unexpected config: %#v", myConfig)
+	}
+	if myConfig.GcpProject != "my-project" || myConfig.GcpRegion != "europe-west6" || myConfig.Port != 8080 {
+		t.Errorf("unexpected config: %#v", myConfig)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests to the metadata server, got: %d", requests)
+	}
+}
+
+func TestGoogleCloudNotOnPlatform(t *testing.T) {
+	g := &GoogleCloud{Base: MapLookuper{}, MetadataHost: "http://127.0.0.1:1"}
+	for _, name := range []string{"GCP_PLATFORM", "GCP_PROJECT", "GCP_REGION", "PORT"} {
+		if val, ok, err := g.LookupErr(name); ok || err != nil {
+			t.Errorf("expected %s to be absent, got: %q (%v)", name, val, err)
+		}
+	}
+}
+
+func TestGoogleCloudConcurrent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/computeMetadata/v1/project/project-id":
+			w.Write([]byte("my-project"))
+		case "/computeMetadata/v1/instance/region":
+			w.Write([]byte("projects/123456/regions/europe-west6"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	g := &GoogleCloud{Base: MapLookuper{"K_SERVICE": "billing"}, MetadataHost: srv.URL}
+	concurrently(t, 20, func() error {
+		var myConfig struct {
+			GcpProject string
+			GcpRegion  string
+		}
+		if err := Load(&myConfig, WithLookuper(g)); err != nil {
+			return err
+		}
+		if myConfig.GcpProject != "my-project" || myConfig.GcpRegion != "europe-west6" {
+			return fmt.Errorf("unexpected config: %#v", myConfig)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,236 @@
+package parsenv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HTTPSource is a Source serving variables fetched from an HTTP endpoint.
+// The response must either be a JSON object (Content-Type application/json),
+// whose values are strings, numbers, or booleans, or else be in dotenv
+// format.
+//
+// The variables are fetched on the first lookup, or explicitly with Fetch.
+// To keep them up to date, run Poll in the background:
+//
+//	src := &parsenv.HTTPSource{URL: "https://config.internal/billing"}
+//	if err := parsenv.Load(&cfg, parsenv.WithLookuper(src)); err != nil {
+//		log.Fatal(err)
+//	}
+//	go src.Poll(ctx, parsenv.PollOptions{Interval: time.Minute}, func() {
+//		var newCfg Config
+//		if err := parsenv.Load(&newCfg, parsenv.WithLookuper(src)); err == nil {
+//			// swap in newCfg
+//		}
+//	})
+//
+// Requests are conditional (If-None-Match), so polling an unchanged
+// configuration is cheap for both sides.
+// An HTTPSource is safe for concurrent use.
+type HTTPSource struct {
+	URL    string
+	Header http.Header // additional request headers, e.g. for authentication
+
+	// Client is used for requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+
+	mu      sync.RWMutex
+	fetched bool
+	vars    map[string]string
+	etag    string
+}
+
+// PollOptions configure HTTPSource.Poll.
+type PollOptions struct {
+	// Interval between two requests. Defaults to one minute.
+	Interval time.Duration
+
+	// Jitter randomizes each interval by up to ±Jitter (a fraction of the
+	// interval, e.g. 0.1 for ±10%), so that large fleets don't poll in
+	// lockstep. Defaults to 0.1; set it to a negative value to disable
+	// jitter.
+	Jitter float64
+
+	// MaxBackoff caps the interval, which is doubled after each failed
+	// request, until a request succeeds again. Defaults to ten times
+	// Interval.
+	MaxBackoff time.Duration
+
+	// OnError, if set, is called with the error of each failed request.
+	OnError func(error)
+}
+
+// Lookup is like LookupErr, but treats errors as absent values.
+func (s *HTTPSource) Lookup(name string) (string, bool) {
+	val, ok, _ := s.LookupErr(name)
+	return val, ok
+}
+
+// LookupErr returns the value of the variable name, fetching the variables
+// first if that hasn't happened yet.
+func (s *HTTPSource) LookupErr(name string) (string, bool, error) {
+	s.mu.RLock()
+	fetched := s.fetched
+	s.mu.RUnlock()
+	if !fetched {
+		if _, err := s.Fetch(context.Background()); err != nil {
+			return "", false, err
+		}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, ok := s.vars[name]
+	return val, ok, nil
+}
+
+// Snapshot returns the variables as they are now, fetching them first if
+// that hasn't happened yet.
+func (s *HTTPSource) Snapshot() (Lookuper, error) {
+	s.mu.RLock()
+	fetched := s.fetched
+	s.mu.RUnlock()
+	if !fetched {
+		if _, err := s.Fetch(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return MapLookuper(maps.Clone(s.vars)), nil
+}
+
+// Fetch requests the variables from the endpoint. It reports whether they
+// changed since the last successful request.
+func (s *HTTPSource) Fetch(ctx context.Context) (changed bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return false, err
+	}
+	for key, vals := range s.Header {
+		req.Header[key] = vals
+	}
+	s.mu.RLock()
+	if s.etag != "" {
+		req.Header.Set("If-None-Match", s.etag)
+	}
+	s.mu.RUnlock()
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return false, nil
+	case http.StatusOK:
+	default:
+		return false, fmt.Errorf("%s: unexpected status: %s", s.URL, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+	var vars map[string]string
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "application/json" {
+		vars, err = decodeJSONVars(body)
+	} else {
+		vars, err = ParseDotenv(bytes.NewReader(body))
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", s.URL, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed = !s.fetched || !maps.Equal(s.vars, vars)
+	s.fetched = true
+	s.vars = vars
+	s.etag = resp.Header.Get("ETag")
+	return changed, nil
+}
+
+// Poll fetches the variables periodically, until ctx is canceled, and calls
+// onChange (if not nil) whenever they changed. It always returns the error
+// of the context.
+func (s *HTTPSource) Poll(ctx context.Context, opts PollOptions, onChange func()) error {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+	if opts.Jitter == 0 {
+		opts.Jitter = 0.1
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 10 * opts.Interval
+	}
+	interval := opts.Interval
+	for {
+		timer := time.NewTimer(jitter(interval, opts.Jitter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		changed, err := s.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if opts.OnError != nil {
+				opts.OnError(err)
+			}
+			interval = min(2*interval, opts.MaxBackoff)
+			continue
+		}
+		interval = opts.Interval
+		if changed && onChange != nil {
+			onChange()
+		}
+	}
+}
+
+// jitter randomizes d by up to ±fraction of d.
+func jitter(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return d
+	}
+	return d + time.Duration((rand.Float64()*2-1)*fraction*float64(d))
+}
+
+func decodeJSONVars(body []byte) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+	vars := make(map[string]string, len(raw))
+	for key, msg := range raw {
+		var str string
+		if err := json.Unmarshal(msg, &str); err == nil {
+			vars[key] = str
+			continue
+		}
+		var scalar any
+		if err := json.Unmarshal(msg, &scalar); err != nil {
+			return nil, err
+		}
+		switch scalar.(type) {
+		case float64, bool:
+			vars[key] = string(msg)
+		default:
+			return nil, fmt.Errorf("value of %s must be a string, number, or boolean", key)
+		}
+	}
+	return vars, nil
+}
@@ -0,0 +1,147 @@
+package parsenv
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPSource(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		body    = `{"HOST":"db.internal","PORT":5432,"DEBUG":true}`
+		etag    = `"v1"`
+		fetches int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetches++
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if strings.HasPrefix(body, "{") {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	src := &HTTPSource{URL: srv.URL}
+	var myConfig struct {
+		Host  string
+		Port  int
+		Debug bool
+	}
+	if err := Load(&myConfig, WithLookuper(src)); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.Host != "db.internal" || myConfig.Port != 5432 || !myConfig.Debug {
+		t.Errorf("unexpected config: %#v", myConfig)
+	}
+	if fetches != 1 {
+		t.Errorf("expected 1 fetch, got: %d", fetches)
+	}
+
+	if changed, err := src.Fetch(context.Background()); err != nil || changed {
+		t.Errorf("expected unchanged config, got: %t (%v)", changed, err)
+	}
+
+	mu.Lock()
+	body = "HOST=db2.internal\n"
+	etag = `"v2"`
+	mu.Unlock()
+	if changed, err := src.Fetch(context.Background()); err != nil || !changed {
+		t.Errorf("expected changed config, got: %t (%v)", changed, err)
+	}
+	if val, _ := src.Lookup("HOST"); val != "db2.internal" {
+		t.Errorf("expected db2.internal, got: %s", val)
+	}
+}
+
+func TestHTTPSourcePoll(t *testing.T) {
+	var version atomic.Int32
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		if n == 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if n%3 == 0 {
+			version.Add(1)
+		}
+		w.Write([]byte("VERSION=" + string(rune('0'+version.Load())) + "\n"))
+	}))
+	defer srv.Close()
+
+	src := &HTTPSource{URL: srv.URL}
+	ctx, cancel := context.WithCancel(context.Background())
+	var changes, failures atomic.Int32
+	opts := PollOptions{
+		Interval: time.Millisecond,
+		OnError:  func(error) { failures.Add(1) },
+	}
+	done := make(chan error)
+	go func() {
+		done <- src.Poll(ctx, opts, func() {
+			if changes.Add(1) == 2 {
+				cancel()
+			}
+		})
+	}()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("expected context.Canceled, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		cancel()
+		t.Fatal("poll did not pick up changes")
+	}
+	if failures.Load() != 1 {
+		t.Errorf("expected 1 failure, got: %d", failures.Load())
+	}
+}
+
+func TestJitter(t *testing.T) {
+	for range 100 {
+		if d := jitter(time.Second, 0.1); d < 900*time.Millisecond || d > 1100*time.Millisecond {
+			t.Fatalf("expected jitter within ±10%%, got: %s", d)
+		}
+	}
+	if d := jitter(time.Second, -1); d != time.Second {
+		t.Errorf("expected no jitter, got: %s", d)
+	}
+}
+
+func TestHTTPSourceConcurrent(t *testing.T) {
+	var version atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "VERSION=%d\n", version.Add(1))
+	}))
+	defer srv.Close()
+
+	src := &HTTPSource{URL: srv.URL}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- src.Poll(ctx, PollOptions{Interval: time.Millisecond, Jitter: -1}, nil) }()
+	concurrently(t, 50, func() error {
+		var myConfig struct{ Version int }
+		if err := Load(&myConfig, WithLookuper(src)); err != nil {
+			return err
+		}
+		_, err := src.Snapshot()
+		return err
+	})
+	cancel()
+	<-done
+}
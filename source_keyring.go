@@ -0,0 +1,39 @@
+package parsenv
+
+// Keyring is a Source backed by the credential store of the operating
+// system: the Keychain on macOS, the Secret Service (libsecret, e.g. GNOME
+// Keyring or KWallet) on Linux and BSD, and the Credential Manager on
+// Windows. It is meant for desktop applications and developer tooling,
+// which shouldn't keep credentials in plain text.
+//
+// Each variable is stored as a generic password of the service Service, with
+// the variable's name as the account:
+//
+//	# macOS
+//	security add-generic-password -s my-app -a API_TOKEN -w
+//	# Linux
+//	secret-tool store --label='my-app API_TOKEN' service my-app account API_TOKEN
+//	# Windows (target name "my-app:API_TOKEN")
+//	cmdkey /generic:my-app:API_TOKEN /user:API_TOKEN /pass
+//
+//	l := parsenv.MultiLookuper(parsenv.EnvLookuper, parsenv.Keyring{Service: "my-app"})
+//	err := parsenv.Load(&cfg, parsenv.WithLookuper(l))
+//
+// On macOS and Linux the security and secret-tool programs are used,
+// respectively. On Windows, passwords are decoded from UTF-16, the encoding
+// cmdkey and the Credential Manager store them in.
+type Keyring struct {
+	Service string
+}
+
+// Lookup is like LookupErr, but treats errors as absent values.
+func (k Keyring) Lookup(name string) (string, bool) {
+	val, ok, _ := k.LookupErr(name)
+	return val, ok
+}
+
+// LookupErr retrieves the password stored for the account name of the
+// service k.Service. If there is no such password, ok is false.
+func (k Keyring) LookupErr(name string) (val string, ok bool, err error) {
+	return keyringGet(k.Service, name)
+}
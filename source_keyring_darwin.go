@@ -0,0 +1,20 @@
+package parsenv
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func keyringGet(service, account string) (string, bool, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 { // errSecItemNotFound
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("keychain: %w", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), true, nil
+}
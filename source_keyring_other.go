@@ -0,0 +1,9 @@
+//go:build !unix && (!windows || parsenv_nounsafe)
+
+package parsenv
+
+import "errors"
+
+func keyringGet(service, account string) (string, bool, error) {
+	return "", false, errors.New("credential store is not supported on this platform")
+}
@@ -0,0 +1,36 @@
+//go:build unix && !darwin
+
+package parsenv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeyring(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+[ "$3" = my-app ] || exit 2
+case "$5" in
+API_TOKEN) echo s3cr3t ;;
+LOCKED) echo "Cannot unlock the keyring" >&2; exit 1 ;;
+*) exit 1 ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "secret-tool"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	k := Keyring{Service: "my-app"}
+	if val, ok, err := k.LookupErr("API_TOKEN"); err != nil || !ok || val != "s3cr3t" {
+		t.Errorf("expected s3cr3t, got: %q (%t, %v)", val, ok, err)
+	}
+	if _, ok, err := k.LookupErr("UNKNOWN"); err != nil || ok {
+		t.Errorf("expected UNKNOWN to be absent, got: %t, %v", ok, err)
+	}
+	if _, _, err := k.LookupErr("LOCKED"); err == nil {
+		t.Error("expected non-nil error, got nil")
+	}
+}
@@ -0,0 +1,30 @@
+//go:build unix && !darwin
+
+package parsenv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func keyringGet(service, account string) (string, bool, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "lookup", "service", service, "account", account)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(out) == 0 && stderr.Len() == 0 {
+			// secret-tool exits with 1, without any output, if nothing was found
+			return "", false, nil
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", false, fmt.Errorf("secret service: %w: %s", err, msg)
+		}
+		return "", false, fmt.Errorf("secret service: %w", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), true, nil
+}
@@ -0,0 +1,60 @@
+//go:build windows && !parsenv_nounsafe
+
+package parsenv
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32     = syscall.NewLazyDLL("advapi32.dll")
+	procCredRead = advapi32.NewProc("CredReadW")
+	procCredFree = advapi32.NewProc("CredFree")
+)
+
+const credTypeGeneric = 1
+
+// credential mirrors the CREDENTIALW structure.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+func keyringGet(service, account string) (string, bool, error) {
+	target, err := syscall.UTF16PtrFromString(service + ":" + account)
+	if err != nil {
+		return "", false, err
+	}
+	var cred *credential
+	ret, _, err := procCredRead.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if ret == 0 {
+		if errors.Is(err, syscall.ERROR_NOT_FOUND) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("credential manager: %w", err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	if cred.CredentialBlobSize == 0 {
+		return "", true, nil
+	}
+	size := cred.CredentialBlobSize
+	if size%2 != 0 {
+		// not written as UTF-16 by cmdkey or the Credential Manager
+		return string(unsafe.Slice(cred.CredentialBlob, size)), true, nil
+	}
+	blob := unsafe.Slice((*uint16)(unsafe.Pointer(cred.CredentialBlob)), size/2)
+	return syscall.UTF16ToString(blob), true, nil
+}
@@ -0,0 +1,129 @@
+package parsenv
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// KubernetesDownwardAPI is a Source that standardizes how pods describe
+// themselves via the Downward API. It provides the following names:
+//
+//	POD_NAME             metadata.name
+//	POD_NAMESPACE        metadata.namespace
+//	POD_UID              metadata.uid
+//	POD_IP               status.podIP
+//	POD_SERVICE_ACCOUNT  spec.serviceAccountName
+//	NODE_NAME            spec.nodeName
+//	POD_LABELS           metadata.labels, as key=value pairs separated by commas
+//	POD_ANNOTATIONS      metadata.annotations, as key=value pairs separated by commas
+//
+// Each name is first looked up in Base, so the conventional variables can be
+// exposed with env entries:
+//
+//	env:
+//	  - name: POD_NAME
+//	    valueFrom: {fieldRef: {fieldPath: metadata.name}}
+//	  - name: NODE_NAME
+//	    valueFrom: {fieldRef: {fieldPath: spec.nodeName}}
+//
+// If a variable is not set, KubernetesDownwardAPI falls back to a file in
+// Dir, as mounted by a downwardAPI volume, whose path is the name of the
+// variable without the POD_ prefix in lower case (e.g. name, namespace,
+// labels):
+//
+//	volumes:
+//	  - name: podinfo
+//	    downwardAPI:
+//	      items:
+//	        - {path: name, fieldRef: {fieldPath: metadata.name}}
+//	        - {path: labels, fieldRef: {fieldPath: metadata.labels}}
+//
+// As a last resort, the namespace is read from the service account mount.
+// All other names are absent, so KubernetesDownwardAPI is meant to be
+// combined with another Lookuper:
+//
+//	l := parsenv.MultiLookuper(parsenv.EnvLookuper, parsenv.KubernetesDownwardAPI{})
+//	err := parsenv.Load(&cfg, parsenv.WithLookuper(l))
+type KubernetesDownwardAPI struct {
+	// Base is where the variables are looked up first. If nil, the process
+	// environment is used.
+	Base Lookuper
+
+	// Dir is the mount path of the downwardAPI volume. If empty,
+	// /etc/podinfo is used.
+	Dir string
+}
+
+const k8sServiceAccountNamespace = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// Lookup is like LookupErr, but treats errors as absent values.
+func (k KubernetesDownwardAPI) Lookup(name string) (string, bool) {
+	val, ok, _ := k.LookupErr(name)
+	return val, ok
+}
+
+// LookupErr returns the value of the Downward API variable name.
+func (k KubernetesDownwardAPI) LookupErr(name string) (string, bool, error) {
+	switch name {
+	default:
+		return "", false, nil
+	case "POD_NAME", "POD_NAMESPACE", "POD_UID", "POD_IP", "POD_SERVICE_ACCOUNT", "NODE_NAME", "POD_LABELS", "POD_ANNOTATIONS":
+	}
+	base := k.Base
+	if base == nil {
+		base = EnvLookuper
+	}
+	if val, ok, err := lookupErr(base, name); err != nil || ok {
+		return val, ok, err
+	}
+	dir := k.Dir
+	if dir == "" {
+		dir = "/etc/podinfo"
+	}
+	val, ok, err := readOptionalFile(filepath.Join(dir, strings.ToLower(strings.TrimPrefix(name, "POD_"))))
+	if err != nil || ok {
+		if name == "POD_LABELS" || name == "POD_ANNOTATIONS" {
+			val = joinDownwardAPIMap(val)
+		}
+		return val, ok, err
+	}
+	if name == "POD_NAMESPACE" {
+		return readOptionalFile(k8sServiceAccountNamespace)
+	}
+	return "", false, nil
+}
+
+func readOptionalFile(path string) (string, bool, error) {
+	bs, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSpace(string(bs)), true, nil
+}
+
+// joinDownwardAPIMap converts the contents of a labels or annotations file,
+// consisting of lines in the form key="value", to key=value pairs separated by
+// commas.
+func joinDownwardAPIMap(contents string) string {
+	var pairs []string
+	for _, line := range strings.Split(contents, "\n") {
+		key, val, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		if uq, err := strconv.Unquote(val); err == nil {
+			val = uq
+		}
+		pairs = append(pairs, key+"="+val)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
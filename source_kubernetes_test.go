@@ -0,0 +1,42 @@
+package parsenv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKubernetesDownwardAPI(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "name"), []byte("billing-7d4f9-xk2p\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "labels"), []byte("app=\"billing\"\ntier=\"backend\"\n"), 0644)
+
+	var myConfig struct {
+		PodName   string
+		NodeName  string
+		PodLabels string
+		PodIp     string
+	}
+	k := KubernetesDownwardAPI{
+		Base: MapLookuper{"NODE_NAME": "worker-3"},
+		Dir:  dir,
+	}
+	if err := Load(&myConfig, WithLookuper(k)); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.PodName != "billing-7d4f9-xk2p" {
+		t.Errorf("expected billing-7d4f9-xk2p, got: %s", myConfig.PodName)
+	}
+	if myConfig.NodeName != "worker-3" {
+		t.Errorf("expected worker-3, got: %s", myConfig.NodeName)
+	}
+	if myConfig.PodLabels != "app=billing,tier=backend" {
+		t.Errorf("expected app=billing,tier=backend, got: %s", myConfig.PodLabels)
+	}
+	if myConfig.PodIp != "" {
+		t.Errorf("expected empty POD_IP, got: %s", myConfig.PodIp)
+	}
+	if _, ok := k.Lookup("HOME"); ok {
+		t.Error("expected unrelated variables to be absent")
+	}
+}
@@ -0,0 +1,35 @@
+package parsenv
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+)
+
+// ProcessEnv reads the environment of another process from
+// /proc/<pid>/environ. This is useful for diagnostic tooling that needs to
+// inspect what configuration a running service actually has:
+//
+//	l, err := parsenv.ProcessEnv(pid)
+//	if err != nil {
+//		return err
+//	}
+//	err = parsenv.Load(&serviceCfg, parsenv.WithLookuper(l))
+//
+// Note that this is the environment the process was started with; changes
+// the process made to its own environment are not visible. Reading the
+// environment of processes owned by other users requires privileges.
+// ProcessEnv is only available on Linux.
+func ProcessEnv(pid int) (MapLookuper, error) {
+	bs, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/environ")
+	if err != nil {
+		return nil, err
+	}
+	vars := MapLookuper{}
+	for _, kv := range bytes.Split(bs, []byte{0}) {
+		if key, val, ok := bytes.Cut(kv, []byte{'='}); ok && len(key) > 0 {
+			vars[string(key)] = string(val)
+		}
+	}
+	return vars, nil
+}
@@ -0,0 +1,32 @@
+package parsenv
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestProcessEnv(t *testing.T) {
+	cmd := exec.Command("sleep", "10")
+	cmd.Env = []string{"DB_HOST=db.internal", "EMPTY=", "WITH_EQUALS=a=b"}
+	if err := cmd.Start(); err != nil {
+		t.Skip("cannot start child process:", err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	l, err := ProcessEnv(cmd.Process.Pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, expected := range map[string]string{"DB_HOST": "db.internal", "EMPTY": "", "WITH_EQUALS": "a=b"} {
+		if val, ok := l.Lookup(key); !ok || val != expected {
+			t.Errorf("expected %s=%s, got: %q (%t)", key, expected, val, ok)
+		}
+	}
+
+	if _, err := ProcessEnv(-1); err == nil {
+		t.Error("expected non-nil error, got nil")
+	}
+}
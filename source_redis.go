@@ -0,0 +1,378 @@
+package parsenv
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RedisHash is a Source serving the fields of a Redis hash as variables.
+//
+//	src := &parsenv.RedisHash{Addr: "redis.internal:6379", Key: "config:billing", Batch: true}
+//	if err := parsenv.Load(&cfg, parsenv.WithLookuper(src)); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// Per default every lookup is a single HGET. With Batch set, the whole hash is
+// fetched with HGETALL on the first lookup (or explicitly with Fetch) and
+// cached instead.
+//
+// To keep the cache up to date, run Watch in the background. Watch relies on
+// keyspace notifications, which must be enabled on the server for hash and
+// generic commands (so that deleting or expiring the hash is noticed too),
+// e.g. with `CONFIG SET notify-keyspace-events Khg`.
+//
+// Only the plain RESP protocol is spoken; connections are not encrypted.
+// A RedisHash is safe for concurrent use.
+type RedisHash struct {
+	Addr     string // host:port of the server, defaults to localhost:6379
+	Username string // username for AUTH, requires Password
+	Password string // password for AUTH, if not empty
+	DB       int    // database number to SELECT
+	Key      string // key of the hash
+	Batch    bool   // fetch the whole hash at once with HGETALL
+
+	// Timeout limits each command, including dialing the connection if
+	// necessary. Defaults to five seconds.
+	Timeout time.Duration
+
+	mu      sync.Mutex
+	conn    *redisConn
+	fetched bool
+	vars    map[string]string
+}
+
+// Lookup is like LookupErr, but treats errors as absent values.
+func (r *RedisHash) Lookup(name string) (string, bool) {
+	val, ok, _ := r.LookupErr(name)
+	return val, ok
+}
+
+// LookupErr returns the value of the hash field name.
+func (r *RedisHash) LookupErr(name string) (string, bool, error) {
+	if !r.Batch {
+		reply, err := r.do(context.Background(), "HGET", r.Key, name)
+		if err != nil {
+			return "", false, err
+		}
+		val, ok := reply.(string)
+		return val, ok, nil
+	}
+	r.mu.Lock()
+	fetched := r.fetched
+	r.mu.Unlock()
+	if !fetched {
+		if _, err := r.Fetch(context.Background()); err != nil {
+			return "", false, err
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	val, ok := r.vars[name]
+	return val, ok, nil
+}
+
+// Fetch reads the whole hash with HGETALL into the cache used when Batch is
+// set. It reports whether the hash changed since the last successful call.
+func (r *RedisHash) Fetch(ctx context.Context) (changed bool, err error) {
+	vars, err := r.getAll(ctx)
+	if err != nil {
+		return false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changed = !r.fetched || !maps.Equal(r.vars, vars)
+	r.fetched = true
+	r.vars = vars
+	return changed, nil
+}
+
+// Snapshot returns the fields of the hash as they are now. With Batch set,
+// these are the cached fields (which are fetched first if that hasn't
+// happened yet), otherwise the hash is read with a single HGETALL.
+func (r *RedisHash) Snapshot() (Lookuper, error) {
+	if !r.Batch {
+		vars, err := r.getAll(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return MapLookuper(vars), nil
+	}
+	r.mu.Lock()
+	fetched := r.fetched
+	r.mu.Unlock()
+	if !fetched {
+		if _, err := r.Fetch(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return MapLookuper(maps.Clone(r.vars)), nil
+}
+
+// getAll reads the whole hash with HGETALL.
+func (r *RedisHash) getAll(ctx context.Context) (map[string]string, error) {
+	reply, err := r.do(ctx, "HGETALL", r.Key)
+	if err != nil {
+		return nil, err
+	}
+	items, _ := reply.([]any)
+	if len(items)%2 != 0 {
+		return nil, fmt.Errorf("redis: malformed HGETALL reply")
+	}
+	vars := make(map[string]string, len(items)/2)
+	for i := 0; i < len(items); i += 2 {
+		key, _ := items[i].(string)
+		val, _ := items[i+1].(string)
+		vars[key] = val
+	}
+	return vars, nil
+}
+
+// Watch subscribes to keyspace notifications of the hash, until ctx is
+// canceled or the subscription fails. On every notification, the cache is
+// refreshed (if Batch is set) and onChange (if not nil) is called.
+// Watch uses a connection of its own.
+func (r *RedisHash) Watch(ctx context.Context, onChange func()) error {
+	conn, err := r.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	channel := fmt.Sprintf("__keyspace@%d__:%s", r.DB, r.Key)
+	if err := conn.writeCommand("SUBSCRIBE", channel); err != nil {
+		return err
+	}
+	for {
+		reply, err := conn.readReply()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		msg, _ := reply.([]any)
+		if len(msg) != 3 || msg[0] != "message" {
+			continue // subscription confirmation
+		}
+		if r.Batch {
+			changed, err := r.Fetch(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return err
+			}
+			if !changed {
+				continue
+			}
+		}
+		if onChange != nil {
+			onChange()
+		}
+	}
+}
+
+// Close closes the connection used for lookups, if any.
+func (r *RedisHash) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conn == nil {
+		return nil
+	}
+	err := r.conn.Close()
+	r.conn = nil
+	return err
+}
+
+// do sends a command, taking the shared connection (or dialing a new one, if
+// it is in use or was discarded) for the duration of the round trip.
+// Connections that fail are discarded, so the next command redials.
+func (r *RedisHash) do(ctx context.Context, args ...string) (any, error) {
+	ctx, cancel := context.WithTimeout(ctx, cmp.Or(r.Timeout, 5*time.Second))
+	defer cancel()
+	r.mu.Lock()
+	conn := r.conn
+	r.conn = nil
+	r.mu.Unlock()
+	if conn == nil {
+		var err error
+		if conn, err = r.dial(ctx); err != nil {
+			return nil, err
+		}
+	}
+	reply, err := conn.doContext(ctx, args...)
+	var rerr redisError
+	if err != nil && !errors.As(err, &rerr) {
+		conn.Close()
+		return nil, err
+	}
+	r.mu.Lock()
+	if r.conn == nil {
+		r.conn = conn
+	} else {
+		conn.Close()
+	}
+	r.mu.Unlock()
+	return reply, err
+}
+
+func (r *RedisHash) dial(ctx context.Context) (*redisConn, error) {
+	addr := r.Addr
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn := &redisConn{Conn: nc, r: bufio.NewReader(nc)}
+	if r.Password != "" {
+		args := []string{"AUTH", r.Password}
+		if r.Username != "" {
+			args = []string{"AUTH", r.Username, r.Password}
+		}
+		if _, err := conn.doContext(ctx, args...); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if r.DB != 0 {
+		if _, err := conn.doContext(ctx, "SELECT", strconv.Itoa(r.DB)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// redisError is an error reply sent by the server.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// redisConn speaks the RESP2 protocol.
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// doContext is like do, but gives up when ctx is done.
+func (c *redisConn) doContext(ctx context.Context, args ...string) (any, error) {
+	deadline, _ := ctx.Deadline()
+	c.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { c.SetDeadline(time.Now()) })
+	defer stop()
+	reply, err := c.do(args...)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		<-ctx.Done() // the deadline is that of ctx, whose timer may lag behind
+	}
+	if err != nil && ctx.Err() != nil {
+		return nil, fmt.Errorf("redis: %w", ctx.Err())
+	}
+	return reply, err
+}
+
+func (c *redisConn) do(args ...string) (any, error) {
+	if err := c.writeCommand(args...); err != nil {
+		return nil, err
+	}
+	reply, err := c.readReply()
+	if err != nil {
+		return nil, err
+	}
+	if rerr, ok := reply.(redisError); ok {
+		return nil, rerr
+	}
+	return reply, nil
+}
+
+func (c *redisConn) writeCommand(args ...string) error {
+	buf := fmt.Appendf(nil, "*%d\r\n", len(args))
+	for _, arg := range args {
+		buf = fmt.Appendf(buf, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := c.Write(buf)
+	return err
+}
+
+// Limits on the replies read, so that a misbehaving server can't make us
+// allocate arbitrary amounts of memory.
+const (
+	maxRedisBulkLength  = 1 << 20 // bytes of a bulk string
+	maxRedisArrayLength = 1 << 16 // items of an array
+)
+
+// readReply reads a single reply. Simple and bulk strings are returned as
+// string, integers as int64, arrays as []any, errors as redisError, and null
+// replies as nil.
+func (c *redisConn) readReply() (any, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply: %q", line)
+	}
+	kind, line := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return line, nil
+	case '-':
+		return redisError(line), nil
+	case ':':
+		return strconv.ParseInt(line, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed bulk length: %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		if n > maxRedisBulkLength {
+			return nil, fmt.Errorf("redis: bulk string of %d bytes exceeds the limit of %d", n, maxRedisBulkLength)
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed array length: %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		if n > maxRedisArrayLength {
+			return nil, fmt.Errorf("redis: array of %d items exceeds the limit of %d", n, maxRedisArrayLength)
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = c.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unknown reply type: %q", kind)
+	}
+}
@@ -0,0 +1,263 @@
+package parsenv
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeRedis serves a single hash over RESP, enough for RedisHash.
+type fakeRedis struct {
+	ln       net.Listener
+	password string
+
+	mu          sync.Mutex
+	hash        map[string]string
+	subscribers []net.Conn
+}
+
+func newFakeRedis(t *testing.T, password string, hash map[string]string) *fakeRedis {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeRedis{ln: ln, password: password, hash: hash}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) serve(nc net.Conn) {
+	defer nc.Close()
+	conn := &redisConn{Conn: nc, r: bufio.NewReader(nc)}
+	authed := f.password == ""
+	for {
+		req, err := conn.readReply()
+		if err != nil {
+			return
+		}
+		items, _ := req.([]any)
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i], _ = item.(string)
+		}
+		if !authed && args[0] != "AUTH" {
+			fmt.Fprint(nc, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+		f.mu.Lock()
+		switch args[0] {
+		case "AUTH":
+			if args[len(args)-1] == f.password {
+				authed = true
+				fmt.Fprint(nc, "+OK\r\n")
+			} else {
+				fmt.Fprint(nc, "-WRONGPASS invalid password\r\n")
+			}
+		case "HGET":
+			if val, ok := f.hash[args[2]]; ok {
+				fmt.Fprintf(nc, "$%d\r\n%s\r\n", len(val), val)
+			} else {
+				fmt.Fprint(nc, "$-1\r\n")
+			}
+		case "HGETALL":
+			fmt.Fprintf(nc, "*%d\r\n", 2*len(f.hash))
+			for key, val := range f.hash {
+				fmt.Fprintf(nc, "$%d\r\n%s\r\n$%d\r\n%s\r\n", len(key), key, len(val), val)
+			}
+		case "SUBSCRIBE":
+			fmt.Fprintf(nc, "*3\r\n$9\r\nsubscribe\r\n$%d\r\n%s\r\n:1\r\n", len(args[1]), args[1])
+			f.subscribers = append(f.subscribers, nc)
+		}
+		f.mu.Unlock()
+	}
+}
+
+func (f *fakeRedis) hset(key, val string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hash[key] = val
+	channel := "__keyspace@0__:config:app"
+	for _, nc := range f.subscribers {
+		fmt.Fprintf(nc, "*3\r\n$7\r\nmessage\r\n$%d\r\n%s\r\n$4\r\nhset\r\n", len(channel), channel)
+	}
+}
+
+func (f *fakeRedis) subscribed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.subscribers) > 0
+}
+
+func TestRedisHash(t *testing.T) {
+	f := newFakeRedis(t, "s3cret", map[string]string{"HOST": "db.internal", "PORT": "6543"})
+	src := &RedisHash{Addr: f.ln.Addr().String(), Password: "s3cret", Key: "config:app"}
+	defer src.Close()
+
+	var myConfig struct {
+		Host    string `cfg:"required"`
+		Port    int
+		Timeout int `cfg:"default=30"`
+	}
+	if err := Load(&myConfig, WithLookuper(src)); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.Host != "db.internal" || myConfig.Port != 6543 || myConfig.Timeout != 30 {
+		t.Errorf("unexpected config: %#v", myConfig)
+	}
+
+	src.Password = "wrong"
+	src.Close()
+	if _, _, err := src.LookupErr("HOST"); err == nil {
+		t.Error("expected an error for a wrong password")
+	}
+}
+
+func TestRedisHashTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		// accepts connections, but never replies
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	src := &RedisHash{Addr: ln.Addr().String(), Key: "config:app", Timeout: 50 * time.Millisecond}
+	defer src.Close()
+
+	done := make(chan error, 2)
+	for range 2 {
+		go func() {
+			_, _, err := src.LookupErr("HOST")
+			done <- err
+		}()
+	}
+	for range 2 {
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected context.DeadlineExceeded, got: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected the lookups to time out")
+		}
+	}
+}
+
+func TestRedisHashBatchWatch(t *testing.T) {
+	f := newFakeRedis(t, "", map[string]string{"HOST": "db.internal"})
+	src := &RedisHash{Addr: f.ln.Addr().String(), Key: "config:app", Batch: true}
+	defer src.Close()
+
+	if val, ok := src.Lookup("HOST"); !ok || val != "db.internal" {
+		t.Errorf("expected db.internal, got: %s", val)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan struct{}, 1)
+	done := make(chan error)
+	go func() { done <- src.Watch(ctx, func() { changes <- struct{}{} }) }()
+	for !f.subscribed() {
+		time.Sleep(time.Millisecond)
+	}
+	f.hset("HOST", "db2.internal")
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a change notification")
+	}
+	if val, _ := src.Lookup("HOST"); val != "db2.internal" {
+		t.Errorf("expected db2.internal, got: %s", val)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+}
+
+func TestRedisHashSnapshot(t *testing.T) {
+	f := newFakeRedis(t, "", map[string]string{"HOST": "db.internal", "PORT": "6379"})
+	for _, batch := range []bool{false, true} {
+		src := &RedisHash{Addr: f.ln.Addr().String(), Key: "config:app", Batch: batch}
+		snap, err := src.Snapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.hset("HOST", "db2.internal")
+		if val, _ := src.Lookup("HOST"); batch && val != "db.internal" || !batch && val != "db2.internal" {
+			t.Errorf("batch=%t: unexpected value of the source: %s", batch, val)
+		}
+		if val, _ := snap.Lookup("HOST"); val != "db.internal" {
+			t.Errorf("batch=%t: expected the snapshot to keep db.internal, got: %s", batch, val)
+		}
+		if val, _ := snap.Lookup("PORT"); val != "6379" {
+			t.Errorf("batch=%t: expected 6379, got: %s", batch, val)
+		}
+		f.hset("HOST", "db.internal")
+		src.Close()
+	}
+}
+
+func TestRedisHashConcurrent(t *testing.T) {
+	f := newFakeRedis(t, "", map[string]string{"VERSION": "0"})
+	for _, batch := range []bool{false, true} {
+		src := &RedisHash{Addr: f.ln.Addr().String(), Key: "config:app", Batch: batch}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- src.Watch(ctx, nil) }()
+		for !f.subscribed() {
+			time.Sleep(time.Millisecond)
+		}
+		var version atomic.Int32
+		concurrently(t, 20, func() error {
+			f.hset("VERSION", fmt.Sprint(version.Add(1)))
+			var myConfig struct{ Version int }
+			if err := Load(&myConfig, WithLookuper(src)); err != nil {
+				return err
+			}
+			_, err := src.Snapshot()
+			return err
+		})
+		cancel()
+		<-done
+		src.Close()
+	}
+}
+
+func TestRedisReplyLimits(t *testing.T) {
+	for _, reply := range []string{
+		fmt.Sprintf("$%d\r\n", maxRedisBulkLength+1),
+		fmt.Sprintf("*%d\r\n", maxRedisArrayLength+1),
+	} {
+		conn := &redisConn{r: bufio.NewReader(strings.NewReader(reply))}
+		if _, err := conn.readReply(); err == nil || !strings.Contains(err.Error(), "exceeds the limit") {
+			t.Errorf("%q: expected an error about the limit, got: %v", reply, err)
+		}
+	}
+	conn := &redisConn{r: bufio.NewReader(strings.NewReader("$5\r\nhello\r\n"))}
+	if reply, err := conn.readReply(); err != nil || reply != "hello" {
+		t.Errorf("expected hello, got: %v (%v)", reply, err)
+	}
+}
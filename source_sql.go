@@ -0,0 +1,114 @@
+package parsenv
+
+import (
+	"context"
+	"database/sql"
+	"maps"
+	"sync"
+)
+
+// SQLSource is a Source serving variables stored as rows of a database
+// table, e.g. settings that are editable in an admin panel.
+//
+//	src := &parsenv.SQLSource{DB: db, Table: "settings"}
+//	if err := parsenv.Load(&cfg, parsenv.WithLookuper(src)); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// The rows are read on the first lookup, or explicitly with Fetch, and
+// cached. Rows with a NULL value are treated as absent.
+// An SQLSource is safe for concurrent use.
+type SQLSource struct {
+	DB *sql.DB
+
+	// Query selects the rows, with the variable name in the first and the
+	// value in the second column. Args are passed to the query.
+	// Defaults to "SELECT name, value FROM <Table>".
+	Query string
+	Args  []any
+
+	// Table used by the default query. Defaults to "settings".
+	// The name is not quoted or escaped.
+	Table string
+
+	mu      sync.RWMutex
+	fetched bool
+	vars    map[string]string
+}
+
+// Lookup is like LookupErr, but treats errors as absent values.
+func (s *SQLSource) Lookup(name string) (string, bool) {
+	val, ok, _ := s.LookupErr(name)
+	return val, ok
+}
+
+// LookupErr returns the value of the variable name, reading the rows first
+// if that hasn't happened yet.
+func (s *SQLSource) LookupErr(name string) (string, bool, error) {
+	s.mu.RLock()
+	fetched := s.fetched
+	s.mu.RUnlock()
+	if !fetched {
+		if _, err := s.Fetch(context.Background()); err != nil {
+			return "", false, err
+		}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, ok := s.vars[name]
+	return val, ok, nil
+}
+
+// Snapshot returns the variables as they are now, reading the rows first if
+// that hasn't happened yet.
+func (s *SQLSource) Snapshot() (Lookuper, error) {
+	s.mu.RLock()
+	fetched := s.fetched
+	s.mu.RUnlock()
+	if !fetched {
+		if _, err := s.Fetch(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return MapLookuper(maps.Clone(s.vars)), nil
+}
+
+// Fetch reads the rows from the database. It reports whether they changed
+// since the last successful call.
+func (s *SQLSource) Fetch(ctx context.Context) (changed bool, err error) {
+	query := s.Query
+	if query == "" {
+		table := s.Table
+		if table == "" {
+			table = "settings"
+		}
+		query = "SELECT name, value FROM " + table
+	}
+	rows, err := s.DB.QueryContext(ctx, query, s.Args...)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	vars := map[string]string{}
+	for rows.Next() {
+		var name string
+		var val sql.NullString
+		if err := rows.Scan(&name, &val); err != nil {
+			return false, err
+		}
+		if val.Valid {
+			vars[name] = val.String
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed = !s.fetched || !maps.Equal(s.vars, vars)
+	s.fetched = true
+	s.vars = vars
+	return changed, nil
+}
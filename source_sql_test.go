@@ -0,0 +1,106 @@
+package parsenv
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+)
+
+// fakeSQLDriver answers every query with the rows of fakeSQLRows, recording
+// the last query.
+type fakeSQLDriver struct{}
+
+var (
+	fakeSQLRows  [][]driver.Value
+	fakeSQLQuery string
+)
+
+func init() {
+	sql.Register("parsenv_fake", fakeSQLDriver{})
+}
+
+func (fakeSQLDriver) Open(string) (driver.Conn, error) { return fakeSQLConn{}, nil }
+
+type fakeSQLConn struct{}
+
+func (fakeSQLConn) Prepare(query string) (driver.Stmt, error) {
+	fakeSQLQuery = query
+	return fakeSQLStmt{}, nil
+}
+func (fakeSQLConn) Close() error              { return nil }
+func (fakeSQLConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type fakeSQLStmt struct{}
+
+func (fakeSQLStmt) Close() error                               { return nil }
+func (fakeSQLStmt) NumInput() int                              { return -1 }
+func (fakeSQLStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (fakeSQLStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeSQLResult{rows: fakeSQLRows}, nil
+}
+
+type fakeSQLResult struct{ rows [][]driver.Value }
+
+func (r *fakeSQLResult) Columns() []string { return []string{"name", "value"} }
+func (r *fakeSQLResult) Close() error      { return nil }
+func (r *fakeSQLResult) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSQLSource(t *testing.T) {
+	db, err := sql.Open("parsenv_fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	fakeSQLRows = [][]driver.Value{
+		{"MAINTENANCE_MODE", "yes"},
+		{"MAX_UPLOAD", "20"},
+		{"BANNER", nil},
+	}
+	src := &SQLSource{DB: db}
+
+	var myConfig struct {
+		MaintenanceMode bool
+		MaxUpload       int
+		Banner          string `cfg:"default=welcome"`
+	}
+	if err := Load(&myConfig, WithLookuper(src)); err != nil {
+		t.Fatal(err)
+	}
+	if fakeSQLQuery != "SELECT name, value FROM settings" {
+		t.Errorf("unexpected query: %s", fakeSQLQuery)
+	}
+	if !myConfig.MaintenanceMode || myConfig.MaxUpload != 20 || myConfig.Banner != "welcome" {
+		t.Errorf("unexpected config: %#v", myConfig)
+	}
+
+	changed, err := src.Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Error("expected unchanged rows")
+	}
+	snap, err := src.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeSQLRows = [][]driver.Value{{"MAX_UPLOAD", "50"}}
+	if changed, _ := src.Fetch(context.Background()); !changed {
+		t.Error("expected changed rows")
+	}
+	if val, _ := src.Lookup("MAX_UPLOAD"); val != "50" {
+		t.Errorf("expected 50, got: %s", val)
+	}
+	if val, _ := snap.Lookup("MAX_UPLOAD"); val != "20" {
+		t.Errorf("expected the snapshot to keep 20, got: %s", val)
+	}
+}
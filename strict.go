@@ -0,0 +1,55 @@
+package parsenv
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// strictInt matches integers without a sign or leading zeros, except for a
+// minus.
+var strictInt = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+
+// checkStrict rejects values for fields tagged `strict` that parseValue
+// would accept leniently. Types other than booleans, integers, and floats are
+// accepted as is.
+func checkStrict(t reflect.Type, val string) error {
+	if isSingleValue(t) {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		if val != "true" && val != "false" {
+			return fmt.Errorf("not a strict boolean value (true or false): %s", val)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !strictInt.MatchString(val) {
+			return fmt.Errorf("not a strict integer value (no sign or leading zeros): %s", val)
+		}
+	case reflect.Float32:
+		f64, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil // reported by parseValue
+		}
+		if math.IsInf(float64(float32(f64)), 0) {
+			return nil // overflow, reported by parseValue
+		}
+		if strconv.FormatFloat(f64, 'g', -1, 64) != strconv.FormatFloat(float64(float32(f64)), 'g', -1, 32) {
+			return fmt.Errorf("value %s loses precision in a float32", val)
+		}
+	}
+	return nil
+}
+
+// trimElem trims whitespace around an element of a slice or map, unless the
+// field is strict.
+func trimElem(s string, td TagData) string {
+	if td.Strict {
+		return s
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,65 @@
+package parsenv
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLoadStrictValues(t *testing.T) {
+	var myConfig struct {
+		Debug   bool
+		Workers int
+		Ratio   float32
+		Hosts   []string
+	}
+	t.Setenv("DEBUG", "yes")
+	t.Setenv("WORKERS", "+08")
+	t.Setenv("RATIO", "0.123456789")
+	t.Setenv("HOSTS", "a, b")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatalf("expected lenient parsing without strict mode, got: %v", err)
+	}
+
+	err := Load(&myConfig, WithStrictValues())
+	var lerr *LoadError
+	if !errors.As(err, &lerr) || len(lerr.Errs) != 3 {
+		t.Fatalf("expected three errors, got: %v", err)
+	}
+	for i, name := range []string{"DEBUG", "WORKERS", "RATIO"} {
+		var perr *ParseError
+		if !errors.As(lerr.Errs[i], &perr) || perr.Name != name {
+			t.Errorf("expected a *ParseError for %s, got: %v", name, lerr.Errs[i])
+		}
+	}
+	if expected := []string{"a", " b"}; !reflect.DeepEqual(myConfig.Hosts, expected) {
+		t.Errorf("expected elements not to be trimmed, got: %q", myConfig.Hosts)
+	}
+
+	t.Setenv("DEBUG", "true")
+	t.Setenv("WORKERS", "-8")
+	t.Setenv("RATIO", "0.1")
+	if err := Load(&myConfig, WithStrictValues()); err != nil {
+		t.Errorf("expected strict values to be accepted, got: %v", err)
+	}
+}
+
+func TestLoadStrictTag(t *testing.T) {
+	var myConfig struct {
+		Debug   bool `cfg:"strict"`
+		Verbose bool
+	}
+	t.Setenv("DEBUG", "1")
+	t.Setenv("VERBOSE", "1")
+
+	err := Load(&myConfig)
+	var lerr *LoadError
+	if !errors.As(err, &lerr) || len(lerr.Errs) != 1 {
+		t.Fatalf("expected a single error, got: %v", err)
+	}
+	var perr *ParseError
+	if !errors.As(lerr.Errs[0], &perr) || perr.Name != "DEBUG" {
+		t.Errorf("expected a *ParseError for DEBUG, got: %v", lerr.Errs[0])
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package parsenv
+
+import (
+	"errors"
+	"os"
+)
+
+func setEcho(tty *os.File, on bool) error {
+	return errors.New("hiding input is not supported on this platform")
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package parsenv
+
+import (
+	"os"
+	"os/exec"
+)
+
+func setEcho(tty *os.File, on bool) error {
+	mode := "-echo"
+	if on {
+		mode = "echo"
+	}
+	cmd := exec.Command("stty", mode)
+	cmd.Stdin = tty
+	return cmd.Run()
+}
@@ -0,0 +1,104 @@
+package parsenv
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeWindow is a daily window of time, such as a maintenance window or
+// quiet hours. It is written as "22:00-06:00", optionally followed by the
+// name of a time zone, as in "22:00-06:00 Europe/Zurich". Without a time
+// zone, UTC is used. A window that ends before it starts spans midnight.
+//
+//	var cfg struct {
+//		MaintenanceWindow parsenv.TimeWindow `cfg:"default=02:00-04:00 Europe/Zurich"`
+//	}
+//
+//	if cfg.MaintenanceWindow.Contains(time.Now()) {
+//		// ...
+//	}
+type TimeWindow struct {
+	Start    time.Duration  // start of the window, as wall clock time since midnight
+	End      time.Duration  // end of the window (exclusive), as wall clock time since midnight
+	Location *time.Location // time zone of Start and End, nil means UTC
+}
+
+// ParseTimeWindow parses a TimeWindow, see TimeWindow for the format.
+func ParseTimeWindow(s string) (TimeWindow, error) {
+	var w TimeWindow
+	span, zone, hasZone := strings.Cut(strings.TrimSpace(s), " ")
+	rawStart, rawEnd, ok := strings.Cut(span, "-")
+	if !ok {
+		return w, fmt.Errorf("invalid time window %q: must be like 22:00-06:00", s)
+	}
+	var err error
+	if w.Start, err = parseClock(rawStart); err != nil {
+		return w, fmt.Errorf("invalid time window %q: %w", s, err)
+	}
+	if w.End, err = parseClock(rawEnd); err != nil {
+		return w, fmt.Errorf("invalid time window %q: %w", s, err)
+	}
+	if w.Start == w.End {
+		return w, fmt.Errorf("invalid time window %q: start and end are the same", s)
+	}
+	if hasZone {
+		if w.Location, err = time.LoadLocation(strings.TrimSpace(zone)); err != nil {
+			return w, fmt.Errorf("invalid time window %q: %w", s, err)
+		}
+	}
+	return w, nil
+}
+
+// parseClock parses a wall clock time like 06:00 into the duration since
+// midnight.
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: must be like 06:00", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains reports whether t falls into the window.
+func (w TimeWindow) Contains(t time.Time) bool {
+	loc := w.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	d := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+	if w.Start < w.End {
+		return w.Start <= d && d < w.End
+	}
+	return d >= w.Start || d < w.End
+}
+
+// String formats the window like ParseTimeWindow expects it.
+func (w TimeWindow) String() string {
+	s := formatClock(w.Start) + "-" + formatClock(w.End)
+	if w.Location != nil && w.Location != time.UTC {
+		s += " " + w.Location.String()
+	}
+	return s
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so that Load can parse
+// TimeWindow fields.
+func (w *TimeWindow) UnmarshalText(text []byte) error {
+	parsed, err := ParseTimeWindow(string(text))
+	if err != nil {
+		return err
+	}
+	*w = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (w TimeWindow) MarshalText() ([]byte, error) {
+	return []byte(w.String()), nil
+}
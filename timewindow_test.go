@@ -0,0 +1,51 @@
+package parsenv
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLoadTimeWindow(t *testing.T) {
+	var myConfig struct {
+		QuietHours        TimeWindow
+		MaintenanceWindow TimeWindow `cfg:"default=02:00-04:00 Europe/Zurich"`
+	}
+	t.Setenv("QUIET_HOURS", "22:00-06:00")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	quiet := myConfig.QuietHours
+	if quiet.Start != 22*time.Hour || quiet.End != 6*time.Hour || quiet.Location != nil {
+		t.Errorf("unexpected QUIET_HOURS: %#v", quiet)
+	}
+	for _, tc := range []struct {
+		hour     int
+		expected bool
+	}{{23, true}, {3, true}, {6, false}, {12, false}, {22, true}} {
+		if got := quiet.Contains(time.Date(2024, 3, 1, tc.hour, 0, 0, 0, time.UTC)); got != tc.expected {
+			t.Errorf("expected Contains(%02d:00) to be %t", tc.hour, tc.expected)
+		}
+	}
+
+	maintenance := myConfig.MaintenanceWindow
+	if maintenance.String() != "02:00-04:00 Europe/Zurich" {
+		t.Errorf("expected 02:00-04:00 Europe/Zurich, got: %s", maintenance)
+	}
+	if maintenance.Contains(time.Date(2024, 3, 1, 3, 30, 0, 0, time.UTC)) {
+		t.Error("expected 03:30 UTC (04:30 in Zurich) to be outside of the maintenance window")
+	}
+	if !maintenance.Contains(time.Date(2024, 3, 1, 1, 30, 0, 0, time.UTC)) {
+		t.Error("expected 01:30 UTC (02:30 in Zurich) to be in the maintenance window")
+	}
+
+	for _, invalid := range []string{"22:00", "25:00-06:00", "22:00-22:00", "22:00-06:00 Mars/Olympus"} {
+		t.Setenv("QUIET_HOURS", invalid)
+		err := Load(&myConfig)
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Name != "QUIET_HOURS" {
+			t.Errorf("expected a *ParseError for %q, got: %v", invalid, err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package parsenv
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// A Transform rewrites a value before it is parsed, see RegisterTransform.
+type Transform func(val string) (string, error)
+
+var (
+	transformsMu sync.RWMutex
+	transforms   = map[string]Transform{
+		"lower": func(val string) (string, error) { return strings.ToLower(val), nil },
+		"upper": func(val string) (string, error) { return strings.ToUpper(val), nil },
+		"trim":  func(val string) (string, error) { return strings.TrimSpace(val), nil },
+	}
+)
+
+// RegisterTransform makes the Transform fn available to fields tagged
+// `transform=<name>`, for one-off massaging of values without a custom type:
+//
+//	func init() {
+//		parsenv.RegisterTransform("basename", func(val string) (string, error) {
+//			return path.Base(val), nil
+//		})
+//	}
+//
+//	var cfg struct {
+//		Binary string `cfg:"transform=basename,lower"` // BINARY=/usr/bin/Foo is loaded as foo
+//	}
+//
+// A field may list several transforms, which are applied in order, before
+// the value is parsed (and checked against `charset`), to values of the
+// variable as well as to the default value. The transforms lower, upper,
+// and trim (of surrounding whitespace) are registered per default.
+// Like database/sql.Register, RegisterTransform is meant to be called from
+// init functions, and panics if a Transform with the same name is already
+// registered, or if fn is nil.
+func RegisterTransform(name string, fn Transform) {
+	if name == "" {
+		panic("parsenv: RegisterTransform with empty name")
+	}
+	if fn == nil {
+		panic(fmt.Sprintf("parsenv: RegisterTransform(%q) with nil Transform", name))
+	}
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+	if _, dup := transforms[name]; dup {
+		panic(fmt.Sprintf("parsenv: RegisterTransform called twice for %q", name))
+	}
+	transforms[name] = fn
+}
+
+// lookupTransform returns the Transform registered as name.
+func lookupTransform(name string) (Transform, bool) {
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+	fn, ok := transforms[name]
+	return fn, ok
+}
+
+// checkTransforms reports transforms listed in td that aren't registered.
+func checkTransforms(td TagData) error {
+	for _, name := range td.Transform {
+		if _, ok := lookupTransform(name); !ok {
+			return fmt.Errorf("unknown transform: %q (register it with RegisterTransform)", name)
+		}
+	}
+	return nil
+}
+
+// applyTransforms applies the transforms listed in td to val, in order.
+func applyTransforms(val string, td TagData) (string, error) {
+	for _, name := range td.Transform {
+		fn, ok := lookupTransform(name)
+		if !ok {
+			return "", fmt.Errorf("unknown transform: %q", name)
+		}
+		var err error
+		if val, err = fn(val); err != nil {
+			return "", fmt.Errorf("transform %s: %w", name, err)
+		}
+	}
+	return val, nil
+}
@@ -0,0 +1,50 @@
+package parsenv
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"testing"
+)
+
+func init() {
+	RegisterTransform("test-basename", func(val string) (string, error) {
+		return path.Base(val), nil
+	})
+	RegisterTransform("test-nonempty", func(val string) (string, error) {
+		if val == "" {
+			return "", errors.New("empty after transforming")
+		}
+		return val, nil
+	})
+}
+
+func TestLoadTransform(t *testing.T) {
+	var myConfig struct {
+		Binary string `cfg:"transform=test-basename,lower"`
+		Region string `cfg:"transform=trim,upper;oneof=EU|US;default=eu"`
+		Name   string `cfg:"transform=trim,test-nonempty"`
+	}
+	t.Setenv("BINARY", "/usr/local/bin/MyTool")
+	t.Setenv("NAME", " app ")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.Binary != "mytool" || myConfig.Region != "EU" || myConfig.Name != "app" {
+		t.Errorf("expected the transformed values, got: %+v", myConfig)
+	}
+
+	t.Setenv("NAME", "   ")
+	err := Load(&myConfig)
+	if err == nil || !strings.Contains(err.Error(), "transform test-nonempty: empty after transforming") {
+		t.Errorf("expected the error of the transform, got: %v", err)
+	}
+
+	var unknown struct {
+		Name string `cfg:"transform=nope"`
+	}
+	if err := Load(&unknown); !errors.As(err, new(*TagError)) {
+		t.Errorf("expected a *TagError for an unknown transform, got: %v", err)
+	}
+}
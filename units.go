@@ -0,0 +1,261 @@
+package parsenv
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A UnitSystem describes quantities that are written as a number followed
+// by a unit suffix, like 512MiB or 1.5kW. Numeric fields tagged
+// `unit=<name>`, where name is a UnitSystem registered with RegisterUnits,
+// are loaded as the number of base units.
+//
+//	parsenv.RegisterUnits("power", parsenv.UnitSystem{
+//		Base:  "W",
+//		Units: map[string]float64{"kW": 1e3, "MW": 1e6},
+//	})
+//
+//	var cfg struct {
+//		MaxDraw int64 `cfg:"unit=power"` // MAX_DRAW=1.5kW is loaded as 1500
+//	}
+//
+// The "bytes" UnitSystem is registered per default, see ByteSize.
+// Suffixes are matched exactly, the longest matching suffix wins. Numbers
+// without a suffix are in base units. Integer fields only accept values that
+// are a whole number of base units.
+type UnitSystem struct {
+	// Base is the suffix of the base unit, e.g. "B" for bytes. It may be
+	// empty, if only bare numbers are in base units.
+	Base string
+
+	// Units maps the suffix of each other unit to the number of base units
+	// it stands for.
+	Units map[string]float64
+}
+
+var (
+	unitSystemsMu sync.RWMutex
+	unitSystems   = map[string]UnitSystem{"bytes": byteUnits}
+)
+
+// RegisterUnits makes the UnitSystem us available to fields tagged
+// `unit=<name>`. Like database/sql.Register, it is meant to be called from
+// init functions, and panics if a UnitSystem with the same name is already
+// registered, or if us is invalid.
+func RegisterUnits(name string, us UnitSystem) {
+	if name == "" {
+		panic("parsenv: RegisterUnits with empty name")
+	}
+	for suffix, factor := range us.Units {
+		if suffix == "" || suffix == us.Base {
+			panic(fmt.Sprintf("parsenv: RegisterUnits(%q): invalid suffix %q", name, suffix))
+		}
+		if !(factor > 0) || math.IsInf(factor, 0) {
+			panic(fmt.Sprintf("parsenv: RegisterUnits(%q): %s must stand for a positive number of base units", name, suffix))
+		}
+	}
+	unitSystemsMu.Lock()
+	defer unitSystemsMu.Unlock()
+	if _, dup := unitSystems[name]; dup {
+		panic(fmt.Sprintf("parsenv: RegisterUnits called twice for %q", name))
+	}
+	unitSystems[name] = us
+}
+
+// lookupUnits returns the UnitSystem registered as name.
+func lookupUnits(name string) (UnitSystem, bool) {
+	unitSystemsMu.RLock()
+	defer unitSystemsMu.RUnlock()
+	us, ok := unitSystems[name]
+	return us, ok
+}
+
+// split separates the number of s from its (longest) unit suffix, and
+// returns the number of base units the suffix stands for.
+func (us UnitSystem) split(s string) (number string, factor float64, err error) {
+	s = strings.TrimSpace(s)
+	number, factor = s, 1
+	longest := -1
+	if us.Base != "" && strings.HasSuffix(s, us.Base) {
+		number, longest = s[:len(s)-len(us.Base)], len(us.Base)
+	}
+	for suffix, f := range us.Units {
+		if len(suffix) > longest && strings.HasSuffix(s, suffix) {
+			number, factor, longest = s[:len(s)-len(suffix)], f, len(suffix)
+		}
+	}
+	number = strings.TrimSpace(number)
+	if number == "" {
+		return "", 0, fmt.Errorf("missing number in %q", s)
+	}
+	return number, factor, nil
+}
+
+// Parse returns the number of base units the quantity s stands for.
+func (us UnitSystem) Parse(s string) (float64, error) {
+	number, factor, err := us.split(s)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity %q: %s is not a number", s, number)
+	}
+	return n * factor, nil
+}
+
+// parseInt is like Parse, but exact for quantities that fit into an int64.
+func (us UnitSystem) parseInt(s string) (int64, error) {
+	number, factor, err := us.split(s)
+	if err != nil {
+		return 0, err
+	}
+	if n, err := strconv.ParseInt(number, 10, 64); err == nil && factor == math.Trunc(factor) && factor < math.MaxInt64 {
+		f := int64(factor)
+		if n > math.MaxInt64/f || n < math.MinInt64/f {
+			return 0, fmt.Errorf("quantity %q overflows int64", s)
+		}
+		return n * f, nil
+	}
+	f, err := us.Parse(s)
+	if err != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("quantity %q is not a whole number of base units", s)
+	}
+	if f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("quantity %q overflows int64", s)
+	}
+	return int64(f), nil
+}
+
+// Format formats v base units with the largest unit in which it is at
+// least 1, and either a whole number, or below 1000 with no more than three
+// decimals: 1536 as 1.5KiB, but 1500 as 1500B, if KiB is the largest unit.
+// Of units that are equally large, the one with the longest suffix is used.
+func (us UnitSystem) Format(v float64) string {
+	suffix, factor := us.Base, 0.0
+	fits := func(f float64) bool {
+		q := v / f
+		return math.Abs(q) >= 1 && (q == math.Trunc(q) || math.Abs(q) < 1000 && q*1000 == math.Trunc(q*1000))
+	}
+	if fits(1) {
+		factor = 1
+	}
+	for s, f := range us.Units {
+		if fits(f) && (f > factor || f == factor && (len(s) > len(suffix) || len(s) == len(suffix) && s < suffix)) {
+			suffix, factor = s, f
+		}
+	}
+	if factor == 0 {
+		suffix, factor = us.Base, 1
+	}
+	return strconv.FormatFloat(v/factor, 'f', -1, 64) + suffix
+}
+
+// formatInt is like Format, but exact for any int64.
+func (us UnitSystem) formatInt(v int64) string {
+	if v > -1<<53 && v < 1<<53 {
+		return us.Format(float64(v))
+	}
+	return strconv.FormatInt(v, 10) + us.Base
+}
+
+// example synthesizes a quantity in the smallest unit other than the base
+// unit.
+func (us UnitSystem) example() string {
+	suffix, factor := us.Base, math.Inf(1)
+	for s, f := range us.Units {
+		if f > 1 && (f < factor || f == factor && s < suffix) {
+			suffix, factor = s, f
+		}
+	}
+	return "10" + suffix
+}
+
+// isUnitKind reports whether values of kind k can be tagged `unit`.
+func isUnitKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// unitTarget returns the numeric type the elements of fields of type t are
+// parsed into, looking through pointers and slices.
+func unitTarget(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t
+}
+
+// parseQuantity parses val with the UnitSystem named unit into a value of
+// the numeric type t.
+func parseQuantity(t reflect.Type, val, unit string) (any, error) {
+	us, ok := lookupUnits(unit)
+	if !ok {
+		return nil, fmt.Errorf("unknown unit system: %q", unit)
+	}
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f, err := us.Parse(val)
+		if err != nil {
+			return nil, err
+		}
+		if v.OverflowFloat(f) {
+			return nil, fmt.Errorf("value %s overflows %s", val, t)
+		}
+		v.SetFloat(f)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := us.parseInt(val)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			return nil, fmt.Errorf("value %s is negative, but %s is unsigned", val, t)
+		}
+		if v.OverflowUint(uint64(i)) {
+			return nil, fmt.Errorf("value %s overflows %s", val, t)
+		}
+		v.SetUint(uint64(i))
+	default:
+		i, err := us.parseInt(val)
+		if err != nil {
+			return nil, err
+		}
+		if v.OverflowInt(i) {
+			return nil, fmt.Errorf("value %s overflows %s", val, t)
+		}
+		v.SetInt(i)
+	}
+	return v.Interface(), nil
+}
+
+// formatQuantity formats the number val with the UnitSystem named unit.
+func formatQuantity(val reflect.Value, unit string) (string, error) {
+	us, ok := lookupUnits(unit)
+	if !ok {
+		return "", fmt.Errorf("unknown unit system: %q", unit)
+	}
+	switch val.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return us.Format(val.Float()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if val.Uint() > math.MaxInt64 {
+			return us.Format(float64(val.Uint())), nil
+		}
+		return us.formatInt(int64(val.Uint())), nil
+	default:
+		return us.formatInt(val.Int()), nil
+	}
+}
@@ -0,0 +1,113 @@
+package parsenv
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func init() {
+	RegisterUnits("test-power", UnitSystem{
+		Base:  "W",
+		Units: map[string]float64{"kW": 1e3, "MW": 1e6, "mW": 1e-3},
+	})
+	RegisterUnits("test-requests", UnitSystem{
+		Base:  "req",
+		Units: map[string]float64{"kreq": 1000},
+	})
+}
+
+func TestLoadUnits(t *testing.T) {
+	var myConfig struct {
+		MaxDraw   int64     `cfg:"unit=test-power"`
+		IdleDraw  float64   `cfg:"unit=test-power;default=250mW"`
+		Budget    *uint32   `cfg:"unit=test-requests"`
+		Steps     []int     `cfg:"unit=test-power"`
+		Threshold float32   `cfg:"unit=test-power;default=2"`
+		Unset     *int64    `cfg:"unit=test-power"`
+		Peaks     []float64 `cfg:"unit=test-power;default=1MW,1.5kW"`
+	}
+	t.Setenv("MAX_DRAW", "1.5kW")
+	t.Setenv("BUDGET", "20 kreq")
+	t.Setenv("STEPS", "1W, 2kW,3")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.MaxDraw != 1500 {
+		t.Errorf("expected 1500, got: %d", myConfig.MaxDraw)
+	}
+	if myConfig.IdleDraw != 0.25 {
+		t.Errorf("expected 0.25, got: %v", myConfig.IdleDraw)
+	}
+	if myConfig.Budget == nil || *myConfig.Budget != 20000 {
+		t.Errorf("expected 20000, got: %v", myConfig.Budget)
+	}
+	if !slices.Equal(myConfig.Steps, []int{1, 2000, 3}) {
+		t.Errorf("expected [1 2000 3], got: %v", myConfig.Steps)
+	}
+	if myConfig.Threshold != 2 {
+		t.Errorf("expected 2, got: %v", myConfig.Threshold)
+	}
+	if myConfig.Unset != nil {
+		t.Errorf("expected nil, got: %v", *myConfig.Unset)
+	}
+
+	env, err := CommandEnv(&myConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"MAX_DRAW=1.5kW", "IDLE_DRAW=250mW", "BUDGET=20kreq", "STEPS=1W,2kW,3W", "PEAKS=1MW,1.5kW"} {
+		if !slices.Contains(env, expected) {
+			t.Errorf("expected %s, got: %v", expected, env)
+		}
+	}
+
+	for _, invalid := range []string{"1.5W", "kW", "1.5GW", "10000000000000MW"} {
+		t.Setenv("MAX_DRAW", invalid)
+		err := Load(&myConfig)
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Name != "MAX_DRAW" {
+			t.Errorf("expected a *ParseError for %q, got: %v", invalid, err)
+		}
+	}
+}
+
+func TestLoadUnitsTagErrors(t *testing.T) {
+	var myConfig struct {
+		Unknown int    `cfg:"unit=lightyears"`
+		Name    string `cfg:"unit=test-power"`
+		Draw    int    `cfg:"unit=test-power;default=1mW"`
+	}
+	err := Load(&myConfig)
+	var lerr *LoadError
+	if !errors.As(err, &lerr) || len(lerr.Errs) != 3 {
+		t.Fatalf("expected three errors, got: %v", err)
+	}
+	for i, field := range []string{"Unknown", "Name", "Draw"} {
+		var terr *TagError
+		if !errors.As(lerr.Errs[i], &terr) || terr.Field != field {
+			t.Errorf("expected a *TagError for %s, got: %v", field, lerr.Errs[i])
+		}
+	}
+}
+
+func TestUnitSystem(t *testing.T) {
+	us := UnitSystem{Base: "B", Units: map[string]float64{"KiB": 1 << 10, "MiB": 1 << 20}}
+	for _, tc := range []struct {
+		in       string
+		expected float64
+	}{{"512", 512}, {"512B", 512}, {"1.5KiB", 1536}, {"2 MiB", 2 << 20}, {"-1KiB", -1024}} {
+		if got, err := us.Parse(tc.in); err != nil || got != tc.expected {
+			t.Errorf("expected %s to be %v, got: %v (%v)", tc.in, tc.expected, got, err)
+		}
+	}
+	for _, tc := range []struct {
+		in       float64
+		expected string
+	}{{0, "0B"}, {512, "512B"}, {1536, "1.5KiB"}, {3 << 20, "3MiB"}, {1536 << 10, "1.5MiB"}} {
+		if got := us.Format(tc.in); got != tc.expected {
+			t.Errorf("expected %v to be formatted as %s, got: %s", tc.in, tc.expected, got)
+		}
+	}
+}
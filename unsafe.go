@@ -0,0 +1,18 @@
+//go:build !parsenv_nounsafe
+
+package parsenv
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+const unsafeAllowed = true
+
+func setUnexportedField(field reflect.Value, value any) {
+	reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Set(reflect.ValueOf(value))
+}
+
+func exportField(field reflect.Value) reflect.Value {
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+}
@@ -0,0 +1,68 @@
+package parsenv
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// Usage renders a table of the variables read into cfg, with their types,
+// defaults, whether they are required, and the descriptions given with
+// `usage=<description>`, for CLIs to print on --help, or when required
+// variables are missing:
+//
+//	VARIABLE      TYPE    DEFAULT  EXAMPLE                     REQUIRED  DESCRIPTION
+//	DATABASE_URL  string           postgres://db.internal/app  yes       connection string of the primary database
+//	PORT          int     8080                                           port to listen on
+//
+// Variables without a default show an example value instead (see
+// FieldInfo.Example). Defaults of secret fields are redacted. cfg may be a struct or a pointer to
+// a struct, otherwise Usage panics. Problems with the struct definition, such
+// as invalid tags, are returned as a *LoadError, like by Describe.
+func Usage(cfg any, opts ...Option) (string, error) {
+	infos, err := Describe(cfg, opts...)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "VARIABLE\tTYPE\tDEFAULT\tEXAMPLE\tREQUIRED\tDESCRIPTION")
+	for _, fi := range infos {
+		def, example := fi.Tag.Default, ""
+		if fi.Tag.Secret && def != "" {
+			def = redacted
+		}
+		if def == "" || def == redacted {
+			example = fi.Example()
+		}
+		required := ""
+		if fi.Tag.Required {
+			required = "yes"
+		} else if len(fi.Tag.RequiredIn) > 0 {
+			required = "in " + strings.Join(fi.Tag.RequiredIn, ",")
+		} else if fi.Tag.RequiredIf != "" {
+			required = "if " + requiredIfCondition(fi.Tag)
+		}
+		desc := fi.Tag.Usage
+		if fi.Tag.Deprecated {
+			desc = strings.TrimSpace(desc + " (deprecated" + prefixed(": ", fi.Tag.DeprecatedReason) + ")")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", fi.Name, fi.Type, def, example, required, desc)
+	}
+	tw.Flush()
+	// cells are padded even if the description is empty
+	lines := strings.SplitAfter(sb.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \n") + strings.Repeat("\n", strings.Count(line, "\n"))
+	}
+	return strings.Join(lines, ""), nil
+}
+
+// prefixed returns s with prefix prepended, or the empty string if s is
+// empty.
+func prefixed(prefix, s string) string {
+	if s == "" {
+		return ""
+	}
+	return prefix + s
+}
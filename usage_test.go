@@ -0,0 +1,27 @@
+package parsenv
+
+import (
+	"testing"
+)
+
+func TestUsage(t *testing.T) {
+	var myConfig struct {
+		DatabaseUrl string `cfg:"required;example=postgres://db.internal/app;usage=connection string of the primary database"`
+		Port        int    `cfg:"default=8080;usage=port to listen on"`
+		ApiKey      string `cfg:"secret;default=dev-key;requiredIn=prod"`
+		Verbose     bool   `cfg:"deprecated=use LOG_LEVEL"`
+	}
+	expected := `VARIABLE      TYPE    DEFAULT     EXAMPLE                     REQUIRED  DESCRIPTION
+DATABASE_URL  string              postgres://db.internal/app  yes       connection string of the primary database
+PORT          int     8080                                              port to listen on
+API_KEY       string  [REDACTED]  example                     in prod
+VERBOSE       bool                true                                  (deprecated: use LOG_LEVEL)
+`
+	got, err := Usage(&myConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
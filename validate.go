@@ -0,0 +1,150 @@
+package parsenv
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// A Validator checks a loaded value, see RegisterValidator. v has the type
+// of the field, e.g. int for an int field, or *string for a *string field.
+type Validator func(v any) error
+
+var (
+	validatorsMu sync.RWMutex
+	validators   = map[string]Validator{}
+)
+
+// RegisterValidator makes the Validator fn available to fields tagged
+// `validate=<name>`, so that domain-specific checks can be kept next to the
+// struct definition:
+//
+//	func init() {
+//		parsenv.RegisterValidator("port", func(v any) error {
+//			if port := v.(int); port < 1024 {
+//				return fmt.Errorf("port %d is privileged", port)
+//			}
+//			return nil
+//		})
+//	}
+//
+//	var cfg struct {
+//		Port int `cfg:"validate=port"`
+//	}
+//
+// A field may list several validators (`validate=port,even`), which are
+// called in order, after the value was parsed, until one of them fails.
+// Like database/sql.Register, RegisterValidator is meant to be called from
+// init functions, and panics if a Validator with the same name is already
+// registered, or if fn is nil.
+func RegisterValidator(name string, fn Validator) {
+	if name == "" {
+		panic("parsenv: RegisterValidator with empty name")
+	}
+	if fn == nil {
+		panic(fmt.Sprintf("parsenv: RegisterValidator(%q) with nil Validator", name))
+	}
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	if _, dup := validators[name]; dup {
+		panic(fmt.Sprintf("parsenv: RegisterValidator called twice for %q", name))
+	}
+	validators[name] = fn
+}
+
+// lookupValidator returns the Validator registered as name.
+func lookupValidator(name string) (Validator, bool) {
+	validatorsMu.RLock()
+	defer validatorsMu.RUnlock()
+	fn, ok := validators[name]
+	return fn, ok
+}
+
+// checkValidators reports validators listed in td that aren't registered.
+func checkValidators(td TagData) error {
+	for _, name := range td.Validate {
+		if _, ok := lookupValidator(name); !ok {
+			return fmt.Errorf("unknown validator: %q (register it with RegisterValidator)", name)
+		}
+	}
+	return nil
+}
+
+// runValidators calls the validators listed in td with v, converted to the
+// field type t.
+func runValidators(t reflect.Type, v any, td TagData) error {
+	if len(td.Validate) == 0 {
+		return nil
+	}
+	v = reflect.ValueOf(v).Convert(t).Interface()
+	for _, name := range td.Validate {
+		fn, ok := lookupValidator(name)
+		if !ok {
+			return fmt.Errorf("unknown validator: %q", name)
+		}
+		if err := fn(v); err != nil {
+			return fmt.Errorf("validator %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// A validatable config type checks its values after they were loaded, see
+// Load.
+type validatable interface {
+	Validate() error
+}
+
+// validateStruct calls the Validate methods of the struct v and of the
+// structs nested in it, innermost first. path is the path of v, e.g.
+// Database. Validate methods of embedded structs are not called directly,
+// since they are promoted to the embedding struct, whose Validate method
+// either is the promoted one, or is responsible for calling it.
+func validateStruct(v reflect.Value, path string, self bool, opts Options) (errs []error) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Tag.Get("cfg") == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() != reflect.Struct || isSingleValue(fv.Type()) {
+			continue
+		}
+		if !fv.CanInterface() {
+			if opts.NoUnsafe || !unsafeAllowed || !fv.CanAddr() {
+				continue
+			}
+			fv = exportField(fv)
+		}
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+		if field.Anonymous {
+			fieldPath = path
+		}
+		errs = append(errs, validateStruct(fv, fieldPath, !field.Anonymous, opts)...)
+	}
+	if !self {
+		return errs
+	}
+	var val any
+	if v.CanAddr() {
+		val = v.Addr().Interface()
+	} else {
+		val = v.Interface()
+	}
+	if vv, ok := val.(validatable); ok {
+		if err := vv.Validate(); err != nil {
+			errs = append(errs, &ValidationError{Field: path, Type: t.String(), Err: err})
+		}
+	}
+	return errs
+}
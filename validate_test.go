@@ -0,0 +1,126 @@
+package parsenv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func init() {
+	RegisterValidator("test-unprivileged", func(v any) error {
+		if port := v.(int); port < 1024 {
+			return fmt.Errorf("port %d is privileged", port)
+		}
+		return nil
+	})
+	RegisterValidator("test-lowercase", func(v any) error {
+		for _, s := range v.([]string) {
+			if s != strings.ToLower(s) {
+				return fmt.Errorf("%s is not lowercase", s)
+			}
+		}
+		return nil
+	})
+}
+
+func TestLoadValidate(t *testing.T) {
+	var myConfig struct {
+		Port  int      `cfg:"validate=test-unprivileged;default=8080"`
+		Hosts []string `cfg:"validate=test-lowercase"`
+	}
+	t.Setenv("HOSTS", "a.example.com,b.example.com")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	if myConfig.Port != 8080 || len(myConfig.Hosts) != 2 {
+		t.Errorf("expected the values to be loaded, got: %+v", myConfig)
+	}
+
+	t.Setenv("PORT", "80")
+	t.Setenv("HOSTS", "a.example.com,B.example.com")
+	err := Load(&myConfig)
+	for _, expected := range []string{
+		"validator test-unprivileged: port 80 is privileged",
+		"validator test-lowercase: B.example.com is not lowercase",
+	} {
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q in the error, got: %v", expected, err)
+		}
+	}
+}
+
+func TestValidateUnknown(t *testing.T) {
+	var myConfig struct {
+		Port int `cfg:"validate=nope"`
+	}
+	if err := Load(&myConfig); !errors.As(err, new(*TagError)) {
+		t.Errorf("expected a *TagError for an unknown validator, got: %v", err)
+	}
+}
+
+func TestRegisterValidatorTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected RegisterValidator to panic for a duplicate name")
+		}
+	}()
+	RegisterValidator("test-unprivileged", func(any) error { return nil })
+}
+
+type tlsConfig struct {
+	Cert string
+	Key  string
+}
+
+func (c tlsConfig) Validate() error {
+	if (c.Cert == "") != (c.Key == "") {
+		return errors.New("cert and key must both be set")
+	}
+	return nil
+}
+
+type serverConfig struct {
+	Tls    tlsConfig
+	Port   int
+	Public bool
+}
+
+func (c *serverConfig) Validate() error {
+	if c.Public && c.Tls.Cert == "" {
+		return errors.New("public servers require TLS")
+	}
+	return nil
+}
+
+func TestLoadValidateHook(t *testing.T) {
+	var myConfig serverConfig
+	t.Setenv("TLS_CERT", "/etc/tls/cert.pem")
+	t.Setenv("PORT", "eighty")
+
+	err := Load(&myConfig)
+	var lerr *LoadError
+	if !errors.As(err, &lerr) || len(lerr.Errs) != 2 {
+		t.Fatalf("expected the parse error and the validation error, got: %v", err)
+	}
+	var verr *ValidationError
+	if !errors.As(lerr.Errs[1], &verr) || verr.Field != "Tls" || verr.Type != "parsenv.tlsConfig" {
+		t.Errorf("expected a *ValidationError for Tls, got: %v", lerr.Errs[1])
+	}
+
+	t.Setenv("TLS_CERT", "")
+	t.Setenv("PORT", "443")
+	t.Setenv("PUBLIC", "true")
+	myConfig = serverConfig{}
+	err = Load(&myConfig)
+	if !errors.As(err, &verr) || verr.Field != "" || verr.Error() != "invalid config parsenv.serverConfig: public servers require TLS" {
+		t.Errorf("expected a *ValidationError for the config, got: %v", err)
+	}
+
+	t.Setenv("TLS_CERT", "/etc/tls/cert.pem")
+	t.Setenv("TLS_KEY", "/etc/tls/key.pem")
+	if err := Load(&myConfig); err != nil {
+		t.Error(err)
+	}
+}
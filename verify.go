@@ -0,0 +1,106 @@
+package parsenv
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// A Verifier checks the contents of a config file before they are trusted,
+// see LoadVerifiedDotenvFiles.
+type Verifier interface {
+	// Verify returns an error if data, the contents of the file name, must
+	// not be used.
+	Verify(name string, data []byte) error
+}
+
+// The VerifierFunc type is an adapter to allow the use of ordinary
+// functions as Verifiers.
+type VerifierFunc func(name string, data []byte) error
+
+// Verify calls f(name, data).
+func (f VerifierFunc) Verify(name string, data []byte) error {
+	return f(name, data)
+}
+
+// LoadVerifiedDotenvFiles is like LoadDotenvFiles, but checks each file with
+// v before parsing it, for deployments that distribute config bundles and
+// must not use tampered ones:
+//
+//	l, err := parsenv.LoadVerifiedDotenvFiles(parsenv.Ed25519Signature{PublicKey: pub}, "app.env")
+//
+// If any file fails verification, no variables are returned.
+func LoadVerifiedDotenvFiles(v Verifier, names ...string) (MapLookuper, error) {
+	if v == nil {
+		panic("parsenv.LoadVerifiedDotenvFiles: nil Verifier")
+	}
+	return loadDotenvFiles(v, names)
+}
+
+// Ed25519Signature is a Verifier checking the detached Ed25519 signature of
+// a file, which is read from the file with .sig appended to its name. The
+// signature is either the raw 64 bytes, or their base64 (standard encoding),
+// like produced by:
+//
+//	openssl pkeyutl -sign -rawin -inkey key.pem -in app.env | base64 > app.env.sig
+type Ed25519Signature struct {
+	PublicKey ed25519.PublicKey
+}
+
+// Verify checks the signature of data in the file name+".sig".
+func (s Ed25519Signature) Verify(name string, data []byte) error {
+	if len(s.PublicKey) != ed25519.PublicKeySize {
+		return errors.New("invalid public key")
+	}
+	sig, err := os.ReadFile(name + ".sig")
+	if err != nil {
+		return err
+	}
+	if len(sig) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+		if err != nil {
+			return fmt.Errorf("invalid signature: %w", err)
+		}
+		sig = decoded
+	}
+	if !ed25519.Verify(s.PublicKey, data, sig) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// checksumPrefix starts the line holding the checksum of the rest of a file,
+// see EmbeddedChecksum.
+const checksumPrefix = "# sha256:"
+
+// EmbeddedChecksum is a Verifier checking the SHA-256 checksum embedded in
+// the first line of a file, as a comment like
+//
+//	# sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+//
+// against the checksum of the remainder of the file. It protects against
+// truncated or corrupted files, but not against deliberate tampering (use
+// Ed25519Signature for that). The line can be added with:
+//
+//	(echo "# sha256:$(sha256sum < body.env | cut -d' ' -f1)"; cat body.env) > app.env
+type EmbeddedChecksum struct{}
+
+// Verify checks the checksum in the first line of data.
+func (EmbeddedChecksum) Verify(name string, data []byte) error {
+	first, rest, _ := bytes.Cut(data, []byte("\n"))
+	want, ok := strings.CutPrefix(strings.TrimSpace(string(first)), checksumPrefix)
+	if !ok {
+		return fmt.Errorf("missing checksum: the first line must be %s<hex>", checksumPrefix)
+	}
+	sum := sha256.Sum256(rest)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(strings.TrimSpace(want), got) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", strings.TrimSpace(want), got)
+	}
+	return nil
+}
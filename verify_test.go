@@ -0,0 +1,79 @@
+package parsenv
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadVerifiedDotenvFilesSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(t.TempDir(), "app.env")
+	data := []byte("HOST=db.example.com\nPORT=5432\n")
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, data))
+	if err := os.WriteFile(name+".sig", []byte(sig+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := LoadVerifiedDotenvFiles(Ed25519Signature{PublicKey: pub}, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l["HOST"] != "db.example.com" || l["PORT"] != "5432" {
+		t.Errorf("expected the variables of the file, got: %v", l)
+	}
+
+	if err := os.WriteFile(name, []byte("HOST=evil.example.com\nPORT=5432\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if l, err := LoadVerifiedDotenvFiles(Ed25519Signature{PublicKey: pub}, name); err == nil || !strings.Contains(err.Error(), "invalid signature") || l != nil {
+		t.Errorf("expected a tampered file to be rejected, got: %v, %v", l, err)
+	}
+
+	if err := os.Remove(name + ".sig"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadVerifiedDotenvFiles(Ed25519Signature{PublicKey: pub}, name); err == nil {
+		t.Error("expected an error for a missing signature, got nil")
+	}
+}
+
+func TestLoadVerifiedDotenvFilesChecksum(t *testing.T) {
+	body := "HOST=db.example.com\nPORT=5432\n"
+	sum := sha256.Sum256([]byte(body))
+	name := filepath.Join(t.TempDir(), "app.env")
+	if err := os.WriteFile(name, []byte("# sha256:"+hex.EncodeToString(sum[:])+"\n"+body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := LoadVerifiedDotenvFiles(EmbeddedChecksum{}, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l["HOST"] != "db.example.com" || l["PORT"] != "5432" {
+		t.Errorf("expected the variables of the file, got: %v", l)
+	}
+
+	for content, expected := range map[string]string{
+		"# sha256:" + hex.EncodeToString(sum[:]) + "\nHOST=db.example.com\n": "checksum mismatch",
+		body: "missing checksum",
+	} {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadVerifiedDotenvFiles(EmbeddedChecksum{}, name); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q, got: %v", expected, err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package parsenv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WeightedItem is an element of a weighted list, like the backends of
+// "a.example.com:3,b.example.com:1". A []WeightedItem field keeps the order
+// of the list, a map[string]int field tagged `weighted` can be used instead
+// if the order doesn't matter.
+//
+// The weight follows the last colon, so values may contain colons
+// themselves ("10.0.0.1:8080:3"). Weights must be positive.
+type WeightedItem struct {
+	Value  string
+	Weight int
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so that Load can parse
+// WeightedItem fields (and slices of them).
+func (wi *WeightedItem) UnmarshalText(text []byte) error {
+	s := string(text)
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return fmt.Errorf("invalid weighted item %q: must be like value:weight", s)
+	}
+	if s[:i] == "" {
+		return fmt.Errorf("invalid weighted item %q: empty value", s)
+	}
+	weight, err := parseWeight(s[i+1:])
+	if err != nil {
+		return fmt.Errorf("invalid weighted item %q: %w", s, err)
+	}
+	*wi = WeightedItem{Value: s[:i], Weight: weight}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (wi WeightedItem) MarshalText() ([]byte, error) {
+	return []byte(wi.String()), nil
+}
+
+// String formats the item as value:weight.
+func (wi WeightedItem) String() string {
+	return wi.Value + ":" + strconv.Itoa(wi.Weight)
+}
+
+// TotalWeight returns the sum of the weights of items, e.g. to pick one of
+// them at random with the probability of its weight.
+func TotalWeight(items []WeightedItem) (total int) {
+	for _, item := range items {
+		total += item.Weight
+	}
+	return total
+}
+
+func parseWeight(s string) (int, error) {
+	weight, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("weight %q is not an integer", s)
+	}
+	if weight <= 0 {
+		return 0, fmt.Errorf("weight %d must be positive", weight)
+	}
+	return weight, nil
+}
+
+// isWeightMap reports whether t can be tagged `weighted`, i.e. is a map with
+// integer values.
+func isWeightMap(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Map {
+		return false
+	}
+	switch t.Elem().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// checkWeight rejects weights of maps tagged `weighted` that aren't
+// positive.
+func checkWeight(weight reflect.Value) error {
+	switch weight.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if weight.Uint() == 0 {
+			return fmt.Errorf("weight 0 must be positive")
+		}
+	default:
+		if weight.Int() <= 0 {
+			return fmt.Errorf("weight %d must be positive", weight.Int())
+		}
+	}
+	return nil
+}
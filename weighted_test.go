@@ -0,0 +1,76 @@
+package parsenv
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLoadWeighted(t *testing.T) {
+	var myConfig struct {
+		Backends []WeightedItem
+		Split    map[string]int   `cfg:"weighted;default=stable:9,canary:1"`
+		Regions  map[string]uint8 `cfg:"weighted;kvsep=="`
+	}
+	t.Setenv("BACKENDS", "10.0.0.1:8080:3, 10.0.0.2:8080:1")
+	t.Setenv("REGIONS", "eu=2,us=1")
+
+	if err := Load(&myConfig); err != nil {
+		t.Fatal(err)
+	}
+	expected := []WeightedItem{{"10.0.0.1:8080", 3}, {"10.0.0.2:8080", 1}}
+	if !slices.Equal(myConfig.Backends, expected) {
+		t.Errorf("expected %v, got: %v", expected, myConfig.Backends)
+	}
+	if total := TotalWeight(myConfig.Backends); total != 4 {
+		t.Errorf("expected a total weight of 4, got: %d", total)
+	}
+	if len(myConfig.Split) != 2 || myConfig.Split["stable"] != 9 || myConfig.Split["canary"] != 1 {
+		t.Errorf("unexpected SPLIT: %v", myConfig.Split)
+	}
+	if len(myConfig.Regions) != 2 || myConfig.Regions["eu"] != 2 || myConfig.Regions["us"] != 1 {
+		t.Errorf("unexpected REGIONS: %v", myConfig.Regions)
+	}
+
+	env, err := CommandEnv(&myConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"BACKENDS=10.0.0.1:8080:3,10.0.0.2:8080:1", "SPLIT=canary:1,stable:9", "REGIONS=eu=2,us=1"} {
+		if !slices.Contains(env, expected) {
+			t.Errorf("expected %s, got: %v", expected, env)
+		}
+	}
+
+	for name, invalid := range map[string]string{
+		"BACKENDS": "a:0",
+		"SPLIT":    "stable:9,canary:-1",
+		"REGIONS":  "eu=0",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, invalid)
+			err := Load(&myConfig)
+			var perr *ParseError
+			if !errors.As(err, &perr) || perr.Name != name {
+				t.Errorf("expected a *ParseError for %s, got: %v", name, err)
+			}
+		})
+	}
+	for _, invalid := range []string{"a", ":3", "a:b", "a:-2"} {
+		var wi WeightedItem
+		if err := wi.UnmarshalText([]byte(invalid)); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestLoadWeightedTagError(t *testing.T) {
+	var myConfig struct {
+		Split map[string]string `cfg:"weighted"`
+	}
+	err := Load(&myConfig)
+	var terr *TagError
+	if !errors.As(err, &terr) || terr.Field != "Split" {
+		t.Errorf("expected a *TagError for Split, got: %v", err)
+	}
+}